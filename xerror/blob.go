@@ -0,0 +1,33 @@
+package xerror
+
+import (
+	"fmt"
+)
+
+// DebugBlob is a binary debug payload attached to an error as a field by `WithDebugBlob`. When the payload exceeds
+// the size cap it is truncated to its first bytes and `Elided` holds the number of bytes dropped. In JSON it is
+// serialized as {"data": "<base64>", "elided": <count>}, with "elided" omitted when nothing was dropped.
+type DebugBlob struct {
+	Data   []byte `json:"data"`
+	Elided int    `json:"elided,omitempty"`
+}
+
+// String returns a short description of the blob, e.g. "16 bytes (+240 elided)".
+func (b DebugBlob) String() string {
+	if b.Elided > 0 {
+		return fmt.Sprintf("%v bytes (+%v elided)", len(b.Data), b.Elided)
+	}
+	return fmt.Sprintf("%v bytes", len(b.Data))
+}
+
+// WithDebugBlob returns a copy of the `Error` with a copy of at most `maxBytes` of `data` stored as a `DebugBlob`
+// field with the given name. A negative `maxBytes` means no cap.
+func (e *xerr) WithDebugBlob(name string, data []byte, maxBytes int) Error {
+	blob := DebugBlob{}
+	if maxBytes >= 0 && len(data) > maxBytes {
+		blob.Elided = len(data) - maxBytes
+		data = data[:maxBytes]
+	}
+	blob.Data = append([]byte{}, data...)
+	return e.WithField(name, blob)
+}
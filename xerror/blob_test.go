@@ -0,0 +1,38 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithDebugBlob(t *testing.T) {
+	data := []byte("request")
+	err := xerror.New("fmt").WithDebugBlob("request", data, 16)
+	data[0] = 'R'
+	assert.Equal(t, xerror.DebugBlob{Data: []byte("request")}, err.Fields()["request"])
+	assert.Equal(t, "7 bytes", err.Fields()["request"].(xerror.DebugBlob).String())
+}
+
+func TestWithDebugBlob_Truncated(t *testing.T) {
+	err := xerror.New("fmt").WithDebugBlob("request", []byte("0123456789"), 4)
+	blob := err.Fields()["request"].(xerror.DebugBlob)
+	assert.Equal(t, xerror.DebugBlob{Data: []byte("0123"), Elided: 6}, blob)
+	assert.Equal(t, "4 bytes (+6 elided)", blob.String())
+}
+
+func TestWithDebugBlob_NoCap(t *testing.T) {
+	err := xerror.New("fmt").WithDebugBlob("request", []byte("0123456789"), -1)
+	assert.Equal(t, xerror.DebugBlob{Data: []byte("0123456789")}, err.Fields()["request"])
+}
+
+func TestWithDebugBlob_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.New("fmt").WithDebugBlob("request", []byte("0123456789"), 4))
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, map[string]interface{}{
+		"request": map[string]interface{}{"data": "MDEyMw==", "elided": float64(6)},
+	}, m["fields"])
+}
@@ -0,0 +1,79 @@
+package xerror
+
+import (
+	"errors"
+	"time"
+)
+
+// Unwrap returns the error wrapped by `Wrap`, or nil if the error was created by `New`.
+func (e *xerr) Unwrap() error {
+	return e.cause
+}
+
+// Cause returns the root cause of the given error, i.e. the innermost error of its chain, found by calling `Unwrap`
+// repeatedly (e.g. the driver or syscall error wrapped by several calls to `Wrap`). It returns `err` itself if it
+// doesn't wrap any error, such as an `Error` created by `New`, and nil if `err` is nil.
+func Cause(err error) error {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
+
+// IsTimeout returns true if the first error in the chain of `err` implementing a `Timeout` method (e.g. a `net.Error`
+// wrapped by `Wrap`) reports a timeout. It returns false if there is none.
+func IsTimeout(err error) bool {
+	var t interface {
+		Timeout() bool
+	}
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// IsTemporary returns true if the first error in the chain of `err` implementing a `Temporary` method (e.g. a
+// `net.Error` wrapped by `Wrap`) reports a temporary failure. It returns false if there is none.
+func IsTemporary(err error) bool {
+	var t interface {
+		Temporary() bool
+	}
+	return errors.As(err, &t) && t.Temporary()
+}
+
+// Replace returns a new augmented error with the given message that replaces `original`, e.g. to present a user-facing
+// error at a boundary. Unlike `Wrap`, the message of `original` is not prepended to the new message: `original` is
+// retained as the cause returned by `Unwrap`, and its message (followed by its debug objects and stack, if it is an
+// `Error`) is appended to the debug objects for logging.
+func Replace(original error, format string, v ...interface{}) Error {
+	e := New(format, v...).(*xerr)
+	if original == nil {
+		return e
+	}
+	e.cause = original
+	e.dbg = append(e.dbg, original.Error())
+	if x, ok := original.(*xerr); ok {
+		e.dbg = append(e.dbg, x.dbg...)
+		e.dbg = append(e.dbg, x.Stack())
+	}
+	return e
+}
+
+// Reframe returns a copy of the `Error` presenting it to a new audience, e.g. to callers at a service boundary: its
+// message is replaced by the given public message (stored verbatim, as by `NewLiteral`), which becomes its only layer,
+// while its original message and message formats are appended to the debug objects. Unlike `Replace`, the stack, code,
+// fields, and other metadata are preserved. The original error is retained as the cause returned by `Unwrap`.
+func (e *xerr) Reframe(publicMessage string) Error {
+	x := e.Clone().(*xerr)
+	publicMessage = internFormat(normalizeFormat(publicMessage))
+	x.cause = e
+	x.msg = publicMessage
+	x.fmts = []string{publicMessage}
+	x.layers = []string{publicMessage}
+	x.lazy = nil
+	x.times = []time.Time{layerTime()}
+	x.wrapped = now()
+	x.codes = []string{e.Code()}
+	x.dbg = append(x.dbg, e.Error(), e.Messages())
+	return x
+}
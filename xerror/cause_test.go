@@ -0,0 +1,126 @@
+package xerror_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"testing"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestUnwrap_New(t *testing.T) {
+	assert.Nil(t, xerror.New("fmt").Unwrap())
+}
+
+func TestUnwrap_NativeErr(t *testing.T) {
+	err := xerror.Wrap(io.EOF, "fmt")
+	assert.Equal(t, io.EOF, err.Unwrap())
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, errors.Is(xerror.Wrap(err, "fmt2"), io.EOF))
+}
+
+func TestUnwrap_Error(t *testing.T) {
+	inner := xerror.New("fmt")
+	err := xerror.Wrap(inner, "fmt2")
+	assert.True(t, err.Unwrap() == inner)
+	assert.True(t, errors.Is(err, inner))
+}
+
+func TestUnwrap_As(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(timeoutErr{}, "fmt"), "fmt2")
+	var target timeoutErr
+	assert.True(t, errors.As(err, &target))
+}
+
+func TestUnwrap_PreservesOuterError(t *testing.T) {
+	inner := xerror.Wrap(io.EOF, "fmt %v", "p1", "d1")
+	err := xerror.Wrap(inner, "fmt2 %v", "p2")
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, "fmt2 p2: fmt p1: EOF", err.Error())
+	assert.Equal(t, []interface{}{"p2", "p1", "d1"}, err.Debug())
+	assert.Equal(t, inner.Stack(), err.Stack())
+}
+
+func TestCause(t *testing.T) {
+	assert.Nil(t, xerror.Cause(nil))
+	assert.Equal(t, io.EOF, xerror.Cause(io.EOF))
+	assert.Equal(t, io.EOF, xerror.Cause(xerror.Wrap(xerror.Wrap(io.EOF, "fmt"), "fmt2")))
+	assert.Equal(t, io.EOF, xerror.Cause(xerror.Wrap(fmt.Errorf("native: %w", io.EOF), "fmt")))
+
+	root := xerror.New("fmt")
+	assert.True(t, xerror.Cause(root) == root)
+	assert.True(t, xerror.Cause(xerror.Wrap(root, "fmt2")) == root)
+}
+
+func TestNetError_NotImplemented(t *testing.T) {
+	_, ok := error(xerror.Wrap(io.EOF, "fmt")).(net.Error)
+	assert.False(t, ok)
+
+	var netErr net.Error
+	assert.True(t, errors.As(xerror.Wrap(xerror.Wrap(timeoutErr{}, "fmt"), "fmt2"), &netErr))
+	assert.True(t, netErr.Timeout())
+}
+
+func TestIsTimeout(t *testing.T) {
+	assert.True(t, xerror.IsTimeout(xerror.Wrap(xerror.Wrap(timeoutErr{}, "fmt"), "fmt2")))
+	assert.True(t, xerror.IsTimeout(timeoutErr{}))
+	assert.False(t, xerror.IsTimeout(xerror.Wrap(io.EOF, "fmt")))
+	assert.False(t, xerror.IsTimeout(nil))
+}
+
+func TestIsTemporary(t *testing.T) {
+	assert.True(t, xerror.IsTemporary(xerror.Wrap(xerror.Wrap(timeoutErr{}, "fmt"), "fmt2")))
+	assert.False(t, xerror.IsTemporary(xerror.Wrap(io.EOF, "fmt")))
+	assert.False(t, xerror.IsTemporary(nil))
+}
+
+func TestReplace_NativeErr(t *testing.T) {
+	err := xerror.Replace(io.EOF, "fmt %v", "p1")
+	assert.Equal(t, "fmt p1", err.Error())
+	assert.Equal(t, []interface{}{"p1", "EOF"}, err.Debug())
+	assert.True(t, xerror.Is(err, "fmt %v"))
+	assert.False(t, err.Contains("EOF"))
+	assert.Equal(t, io.EOF, err.Unwrap())
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestReplace_Error(t *testing.T) {
+	original := xerror.New("internal %v", "p1", "d1")
+	err := xerror.Replace(original, "public")
+	assert.Equal(t, "public", err.Error())
+	assert.Equal(t, []interface{}{"internal p1", "p1", "d1", original.Stack()}, err.Debug())
+	assert.False(t, err.Contains("internal %v"))
+	assert.True(t, err.Unwrap() == original)
+}
+
+func TestReplace_NilErr(t *testing.T) {
+	err := xerror.Replace(nil, "public")
+	assert.Equal(t, "public", err.Error())
+	assert.Nil(t, err.Unwrap())
+}
+
+func TestReframe(t *testing.T) {
+	err := xerror.Wrap(io.EOF, "db: read row %v", 42).
+		WithCode("STORAGE").
+		WithField("k", "v")
+	r := err.Reframe("Service temporarily unavailable (100% of replicas down)")
+	assert.Equal(t, "Service temporarily unavailable (100% of replicas down)", r.Error())
+	assert.True(t, xerror.Is(r, "Service temporarily unavailable (100% of replicas down)"))
+	assert.False(t, r.Contains("db: read row %v"))
+	assert.Equal(t, []interface{}{42, "db: read row 42: EOF", []string{"db: read row %v", "EOF"}}, r.Debug())
+	assert.Equal(t, err.Stack(), r.Stack())
+	assert.Equal(t, "STORAGE", r.Code())
+	assert.Equal(t, map[string]interface{}{"k": "v"}, r.Fields())
+	assert.Equal(t, err.InstanceID(), r.InstanceID())
+	assert.True(t, errors.Is(r, io.EOF))
+	assert.True(t, r.Unwrap() == err)
+	assert.Equal(t, "db: read row 42: EOF", err.Error())
+}
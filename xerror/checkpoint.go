@@ -0,0 +1,48 @@
+package xerror
+
+import (
+	"time"
+)
+
+// DefaultMaxCheckpoints is the maximum number of checkpoints kept by errors, unless changed by `SetMaxCheckpoints`.
+const DefaultMaxCheckpoints = 32
+
+// Checkpoint is a named point in time reached before an error occurred, see `WithCheckpoint`.
+type Checkpoint struct {
+	Name string    `json:"name"`
+	At   time.Time `json:"at"`
+}
+
+// SetMaxCheckpoints sets the maximum number of checkpoints kept by errors: when it is exceeded, `WithCheckpoint` drops
+// the oldest ones, so that recording checkpoints in loops doesn't grow errors without bound. Zero or less restores
+// `DefaultMaxCheckpoints`.
+func SetMaxCheckpoints(n int) {
+	setConfig(func(c *config) {
+		c.maxCheckpoints = n
+	})
+}
+
+// WithCheckpoint returns a copy of the `Error` with the given checkpoint appended to its timeline, e.g. to record when
+// each step of an operation started before it failed. The timeline survives wrapping, and is serialized to JSON as
+// "checkpoints".
+func (e *xerr) WithCheckpoint(name string, at time.Time) Error {
+	x := e.Clone().(*xerr)
+	x.checks = append(x.checks, Checkpoint{Name: name, At: at})
+	if n := maxCheckpoints(); len(x.checks) > n {
+		x.checks = append([]Checkpoint(nil), x.checks[len(x.checks)-n:]...)
+	}
+	return x
+}
+
+// Checkpoints returns a copy of the checkpoints recorded by `WithCheckpoint`, oldest first.
+func (e *xerr) Checkpoints() []Checkpoint {
+	return append([]Checkpoint(nil), e.checks...)
+}
+
+// maxCheckpoints returns the configured maximum number of checkpoints
+func maxCheckpoints() int {
+	if n := getConfig().maxCheckpoints; n > 0 {
+		return n
+	}
+	return DefaultMaxCheckpoints
+}
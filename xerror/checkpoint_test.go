@@ -0,0 +1,46 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithCheckpoint(t *testing.T) {
+	t1, t2 := time.Unix(1000, 0).UTC(), time.Unix(2000, 0).UTC()
+	orig := xerror.New("fmt").WithCheckpoint("start", t1)
+	err := xerror.Wrap(orig, "fmt2").WithCheckpoint("query", t2)
+	assert.Equal(t, []xerror.Checkpoint{{Name: "start", At: t1}, {Name: "query", At: t2}}, err.Checkpoints())
+	assert.Equal(t, []xerror.Checkpoint{{Name: "start", At: t1}}, orig.Checkpoints())
+	assert.Empty(t, xerror.New("fmt").Checkpoints())
+}
+
+func TestWithCheckpoint_Max(t *testing.T) {
+	xerror.SetMaxCheckpoints(3)
+	defer xerror.SetMaxCheckpoints(0)
+
+	err := xerror.New("fmt")
+	for i := 0; i < 5; i++ {
+		err = err.WithCheckpoint(fmt.Sprintf("c%v", i), time.Unix(int64(i), 0))
+	}
+	cps := err.Checkpoints()
+	assert.Len(t, cps, 3)
+	assert.Equal(t, "c2", cps[0].Name)
+	assert.Equal(t, "c4", cps[2].Name)
+}
+
+func TestWithCheckpoint_JSON(t *testing.T) {
+	at := time.Unix(1000, 0).UTC()
+	err := xerror.New("fmt").WithCheckpoint("start", at)
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	assert.Contains(t, string(buf), `"checkpoints":[{"name":"start","at":"1970-01-01T00:16:40Z"}]`)
+
+	e2, e := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, e)
+	assert.Equal(t, []xerror.Checkpoint{{Name: "start", At: at}}, e2.Checkpoints())
+	assert.NotContains(t, xerror.New("fmt").GoString(), `"checkpoints"`)
+}
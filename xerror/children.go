@@ -0,0 +1,141 @@
+package xerror
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrorCombined is the message format of errors created by `Combine`.
+const ErrorCombined = "combined errors"
+
+// WithChildren returns a copy of the `Error` with the given errors appended to its children, e.g. the failures of the
+// sub-operations of a dependency graph. Nil errors are skipped. Unlike wrapped errors, children are not part of the
+// error message: they are returned by `Children`, traversed by `WalkTree`, and serialized to JSON as nested "children"
+// arrays.
+func (e *xerr) WithChildren(children ...error) Error {
+	x := e.Clone().(*xerr)
+	for _, child := range children {
+		if child != nil {
+			x.children = append(x.children, child)
+		}
+	}
+	return x
+}
+
+// Combine returns an error reporting both of the given independent errors, e.g. the failures of two operations run in
+// parallel. Its message is made of both messages separated by "; ", and its format is `ErrorCombined`. Both errors are
+// kept as its children, each with its own stack, and returned by `Children`. If one of the errors is nil, it returns
+// the other (converted to `Error` if needed); if both are nil, it returns nil.
+func Combine(a, b error) Error {
+	switch {
+	case a == nil && b == nil:
+		return nil
+	case a == nil:
+		return asError(b)
+	case b == nil:
+		return asError(a)
+	}
+	x := newXerr(internFormat(normalizeFormat(ErrorCombined)), a.Error()+"; "+b.Error(), []interface{}{}, newStack())
+	x.children = []error{a, b}
+	x.combined = true
+	return x
+}
+
+// Join returns an error reporting all the given independent errors, e.g. the failures of the validation of several
+// fields. Nil errors are skipped, and if all errors are nil it returns nil. Like `Combine`, its message is made of the
+// messages of the errors separated by "; ", its format is `ErrorCombined`, and the errors are kept as its children. Its
+// debug objects are those of the errors, in order, and its stack is the one of the call to `Join`. The errors can be
+// reached by `errors.Is` and `errors.As`, see `Error.Is` and `Error.As`.
+func Join(errs ...error) Error {
+	children := make([]error, 0, len(errs))
+	msgs := make([]string, 0, len(errs))
+	dbg := []interface{}{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		children = append(children, err)
+		msgs = append(msgs, err.Error())
+		if x, ok := err.(*xerr); ok {
+			dbg = append(dbg, x.dbg...)
+		}
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	x := newXerr(internFormat(normalizeFormat(ErrorCombined)), strings.Join(msgs, "; "), dbg, newStack())
+	x.children = children
+	x.combined = true
+	return x
+}
+
+// As finds the first child of the error (see `Children`) that matches `target`, as `errors.As` would, and if one is
+// found sets `target` to it and returns true. It lets `errors.As` reach the errors combined by `Join` and `Combine`.
+func (e *xerr) As(target interface{}) bool {
+	for _, child := range e.children {
+		if errors.As(child, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// asError returns the given error if it is of type `*xerr`, or an `*xerr` with the same message wrapping it otherwise
+func asError(err error) *xerr {
+	if x, ok := err.(*xerr); ok {
+		return x
+	}
+	x := New(err.Error()).(*xerr)
+	x.cause = err
+	return x
+}
+
+// Children returns a copy of the child errors attached to the error.
+func (e *xerr) Children() []error {
+	return append([]error(nil), e.children...)
+}
+
+// WalkTree calls `fn` for the error (at depth 0) and then, depth first, for each of its descendants (at their depth in
+// the tree). The descendants of an error already being visited higher up in the tree are skipped, so that cycles don't
+// cause infinite recursion.
+func (e *xerr) WalkTree(fn func(depth int, err error)) {
+	e.walkTree(fn, 0, map[*xerr]bool{})
+}
+
+// walkTree calls `fn` for the error and its descendants, skipping the children of errors in `path`
+func (e *xerr) walkTree(fn func(int, error), depth int, path map[*xerr]bool) {
+	fn(depth, e)
+	if path[e] {
+		return
+	}
+	path[e] = true
+	defer delete(path, e)
+	for _, child := range e.children {
+		if x, ok := child.(*xerr); ok {
+			x.walkTree(fn, depth+1, path)
+		} else {
+			fn(depth+1, child)
+		}
+	}
+}
+
+// childJSON is used to serialize a Go `error` child to JSON
+type childJSON struct {
+	Message string `json:"message"`
+}
+
+// childrenJSON returns the JSON representations of the given children, or nil if there are none
+func childrenJSON(children []error, path map[*xerr]bool) []interface{} {
+	if len(children) == 0 {
+		return nil
+	}
+	j := make([]interface{}, 0, len(children))
+	for _, child := range children {
+		if x, ok := child.(*xerr); ok {
+			j = append(j, x.toJSON(path))
+		} else {
+			j = append(j, &childJSON{Message: child.Error()})
+		}
+	}
+	return j
+}
@@ -0,0 +1,152 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestWithChildren(t *testing.T) {
+	c1, c2 := xerror.New("c1"), errors.New("c2")
+	err := xerror.New("fmt")
+	cp := err.WithChildren(c1, nil, c2)
+	assert.Empty(t, err.Children())
+	assert.Equal(t, []error{c1, c2}, cp.Children())
+	assert.Equal(t, "fmt", cp.Error())
+	assert.Equal(t, []error{c1, c2}, xerror.Wrap(cp, "fmt2").Children())
+}
+
+func TestWalkTree(t *testing.T) {
+	leaf := xerror.New("leaf")
+	err := xerror.New("root").WithChildren(
+		xerror.New("a").WithChildren(leaf, errors.New("native")),
+		xerror.New("b").WithChildren(leaf))
+
+	visited := []string{}
+	depths := []int{}
+	err.WalkTree(func(depth int, err error) {
+		visited = append(visited, err.Error())
+		depths = append(depths, depth)
+	})
+	assert.Equal(t, []string{"root", "a", "leaf", "native", "b", "leaf"}, visited)
+	assert.Equal(t, []int{0, 1, 2, 2, 1, 2}, depths)
+}
+
+func TestWithChildren_JSON(t *testing.T) {
+	err := xerror.New("root").WithChildren(
+		xerror.New("a").WithChildren(errors.New("native")),
+		xerror.New("b"))
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+
+	type node struct {
+		Message  string `json:"message"`
+		Children []node `json:"children"`
+	}
+	n := node{}
+	assert.Nil(t, json.Unmarshal(buf, &n))
+	assert.Equal(t, node{
+		Message: "root",
+		Children: []node{
+			{Message: "a", Children: []node{{Message: "native"}}},
+			{Message: "b"},
+		},
+	}, n)
+
+	buf, e = json.Marshal(xerror.New("fmt"))
+	assert.Nil(t, e)
+	assert.NotContains(t, string(buf), `"children"`)
+}
+
+func TestCombine(t *testing.T) {
+	a := xerror.New("a")
+	b := errors.New("b")
+	err := xerror.Combine(a, b)
+	assert.Equal(t, "a; b", err.Error())
+	assert.True(t, xerror.Is(err, xerror.ErrorCombined))
+	assert.Equal(t, []error{a, b}, err.Children())
+	assert.Equal(t, a.Stack(), err.Children()[0].(xerror.Error).Stack())
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestCombine", err.StackFrames()[0].Function)
+}
+
+func TestCombine_Nil(t *testing.T) {
+	a := xerror.New("a")
+	assert.Nil(t, xerror.Combine(nil, nil))
+	assert.True(t, xerror.Combine(a, nil) == a)
+	assert.True(t, xerror.Combine(nil, a) == a)
+
+	b := errors.New("b")
+	err := xerror.Combine(nil, b)
+	assert.Equal(t, "b", err.Error())
+	assert.True(t, errors.Is(err, b))
+	assert.Empty(t, err.Children())
+}
+
+func TestJoin(t *testing.T) {
+	sentinel := xerror.New("not found")
+	err := xerror.Join(xerror.New("fmt1 %v", "p1"), nil, io.EOF, xerror.Wrap(sentinel, "fmt2 %v", "p2"))
+	assert.Equal(t, "fmt1 p1; EOF; fmt2 p2: not found", err.Error())
+	assert.True(t, xerror.Is(err, xerror.ErrorCombined))
+	assert.Equal(t, []interface{}{"p1", "p2"}, err.Debug())
+	assert.Len(t, err.Children(), 3)
+	assert.True(t, err.HasStack())
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, errors.Is(err, sentinel))
+	assert.True(t, errors.Is(xerror.Wrap(err, "fmt3"), sentinel))
+	assert.False(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestJoin_IsUnrelated(t *testing.T) {
+	a, b := xerror.Join(errors.New("a")), xerror.Join(errors.New("b"))
+	assert.False(t, errors.Is(a, b))
+	assert.False(t, errors.Is(xerror.Wrap(a, "fmt"), xerror.Wrap(b, "fmt")))
+	assert.False(t, errors.Is(xerror.Combine(xerror.New("a"), xerror.New("b")), xerror.Combine(xerror.New("c"), xerror.New("d"))))
+	assert.True(t, errors.Is(a, a))
+	assert.True(t, errors.Is(xerror.Wrap(a, "fmt"), a))
+}
+
+func TestJoin_IsCombinedFormat(t *testing.T) {
+	sentinel := xerror.New(xerror.ErrorCombined)
+	assert.True(t, errors.Is(xerror.Wrap(xerror.New(xerror.ErrorCombined), "fmt"), xerror.Wrap(sentinel, "fmt")))
+	assert.False(t, errors.Is(xerror.Join(errors.New("a")), sentinel))
+}
+
+func TestJoin_IsUnrelatedClone(t *testing.T) {
+	a, b := xerror.Join(errors.New("a")), xerror.Join(errors.New("b"))
+	assert.False(t, errors.Is(a.Clone(), b))
+	assert.False(t, errors.Is(a.WithField("k", "v"), b))
+}
+
+func TestJoin_IsUnrelatedJSON(t *testing.T) {
+	a, b := xerror.Join(errors.New("a")), xerror.Join(errors.New("b"))
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(a.GoString()), &m))
+	assert.Equal(t, true, m["combined"])
+
+	ra, err := xerror.UnmarshalJSON([]byte(a.GoString()))
+	assert.Nil(t, err)
+	assert.False(t, errors.Is(ra, b))
+
+	rs, err := xerror.UnmarshalJSON([]byte(xerror.New(xerror.ErrorCombined).GoString()))
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(rs, xerror.New(xerror.ErrorCombined)))
+}
+
+func TestJoin_As(t *testing.T) {
+	err := xerror.Join(xerror.New("fmt1"), &os.PathError{Op: "open", Path: "p", Err: io.EOF})
+	var pathErr *os.PathError
+	assert.True(t, errors.As(err, &pathErr))
+	assert.Equal(t, "p", pathErr.Path)
+	var x xerror.Error
+	assert.True(t, errors.As(err, &x))
+	assert.Equal(t, err, x)
+}
+
+func TestJoin_Nil(t *testing.T) {
+	assert.Nil(t, xerror.Join())
+	assert.Nil(t, xerror.Join(nil, nil))
+}
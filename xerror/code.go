@@ -0,0 +1,161 @@
+package xerror
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Kind is a broad category of errors (e.g. "NotFound"), used to group registered codes in catalogs.
+type Kind string
+
+// CodeInfo describes a registered error code.
+type CodeInfo struct {
+	Code        string
+	UserMessage string
+	HTTPStatus  int
+	Kind        Kind
+}
+
+var (
+	codesMu       sync.RWMutex
+	codes         = map[string]CodeInfo{}
+	expectedKinds = map[Kind]bool{}
+)
+
+// RegisterCode registers the canonical user message and HTTP status for the given code. Errors whose `Code` is the code
+// report these defaults unless they were set explicitly. Registering a code again replaces its defaults, preserving its
+// kind. It is safe for concurrent use.
+func RegisterCode(code, userMsg string, httpStatus int) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	info := codes[code]
+	info.Code = code
+	info.UserMessage = userMsg
+	info.HTTPStatus = httpStatus
+	codes[code] = info
+}
+
+// RegisterKind registers the kind of the given code, adding the code to the registry if needed. It is safe for
+// concurrent use.
+func RegisterKind(code string, kind Kind) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	info := codes[code]
+	info.Code = code
+	info.Kind = kind
+	codes[code] = info
+}
+
+// RegisterExpectedKind registers the given kind as expected, i.e. part of the normal operation of the program (e.g.
+// "NotFound"): errors of the kind are treated as if flagged by `MarkExpected`. It is safe for concurrent use.
+func RegisterExpectedKind(kind Kind) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	expectedKinds[kind] = true
+}
+
+// isExpectedKind returns true if the given kind was registered with `RegisterExpectedKind`
+func isExpectedKind(kind Kind) bool {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	return kind != "" && expectedKinds[kind]
+}
+
+// AllCodes returns all registered codes sorted by code, e.g. to generate an error catalog.
+func AllCodes() []CodeInfo {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	all := make([]CodeInfo, 0, len(codes))
+	for _, info := range codes {
+		all = append(all, info)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Code < all[j].Code
+	})
+	return all
+}
+
+// lookupCode returns the defaults registered for the given code, if any
+func lookupCode(code string) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	info, ok := codes[code]
+	return info, ok
+}
+
+// WithCode returns a copy of the `Error` whose outermost layer is tagged with the given code. If the code is
+// registered, `UserMessage` and `HTTPStatus` fall back to its registry defaults unless they were set explicitly.
+func (e *xerr) WithCode(code string) Error {
+	x := e.Clone().(*xerr)
+	x.codes[0] = code
+	return x
+}
+
+// Code returns the code of the outermost layer that has one, or an empty string if no layer has a code.
+func (e *xerr) Code() string {
+	for _, c := range e.codes {
+		if c != "" {
+			return c
+		}
+	}
+	return ""
+}
+
+// ContainsCode returns true if any layer of the error is tagged with the given code, false otherwise.
+func (e *xerr) ContainsCode(code string) bool {
+	return code != "" && containsString(e.codes, code)
+}
+
+// ContainsCode is like the `ContainsCode` method, but accepts any `error`. It returns false for Go errors.
+func ContainsCode(err error, code string) bool {
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.ContainsCode(code)
+	}
+	return false
+}
+
+// HasCode returns true if the code of the first `Error` found in the chain of `err` (see `Code`) is the given code,
+// false otherwise. It returns false for Go errors.
+func HasCode(err error, code string) bool {
+	var x *xerr
+	return code != "" && errors.As(err, &x) && x.Code() == code
+}
+
+// WithUserMessage returns a copy of the `Error` with the given user-facing message, overriding any registry default.
+func (e *xerr) WithUserMessage(msg string) Error {
+	x := e.Clone().(*xerr)
+	x.userMsg = msg
+	return x
+}
+
+// UserMessage returns the user-facing message set with `WithUserMessage`, or else the one registered for the error's
+// code, or an empty string if neither is set.
+func (e *xerr) UserMessage() string {
+	if e.userMsg != "" {
+		return e.userMsg
+	}
+	if info, ok := lookupCode(e.Code()); ok {
+		return info.UserMessage
+	}
+	return ""
+}
+
+// WithHTTPStatus returns a copy of the `Error` with the given HTTP status, overriding any registry default.
+func (e *xerr) WithHTTPStatus(status int) Error {
+	x := e.Clone().(*xerr)
+	x.httpStatus = status
+	return x
+}
+
+// HTTPStatus returns the HTTP status set with `WithHTTPStatus`, or else the one registered for the error's code, and
+// whether either is set.
+func (e *xerr) HTTPStatus() (int, bool) {
+	if e.httpStatus != 0 {
+		return e.httpStatus, true
+	}
+	if info, ok := lookupCode(e.Code()); ok && info.HTTPStatus != 0 {
+		return info.HTTPStatus, true
+	}
+	return 0, false
+}
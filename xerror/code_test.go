@@ -0,0 +1,208 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWithCode_Unregistered(t *testing.T) {
+	err := xerror.New("fmt").WithCode("UNREGISTERED")
+	assert.Equal(t, "UNREGISTERED", err.Code())
+	assert.Equal(t, "", err.UserMessage())
+	_, ok := err.HTTPStatus()
+	assert.False(t, ok)
+}
+
+func TestWithCode_Registered(t *testing.T) {
+	xerror.RegisterCode("REGISTERED", "user message", 404)
+	err := xerror.New("fmt").WithCode("REGISTERED")
+	assert.Equal(t, "REGISTERED", err.Code())
+	assert.Equal(t, "user message", err.UserMessage())
+	status, ok := err.HTTPStatus()
+	assert.True(t, ok)
+	assert.Equal(t, 404, status)
+}
+
+func TestWithCode_ExplicitBeforeCode(t *testing.T) {
+	xerror.RegisterCode("REGISTERED_BEFORE", "user message", 404)
+	err := xerror.New("fmt").WithUserMessage("explicit").WithHTTPStatus(409).WithCode("REGISTERED_BEFORE")
+	assert.Equal(t, "explicit", err.UserMessage())
+	status, _ := err.HTTPStatus()
+	assert.Equal(t, 409, status)
+}
+
+func TestWithCode_ExplicitAfterCode(t *testing.T) {
+	xerror.RegisterCode("REGISTERED_AFTER", "user message", 404)
+	err := xerror.New("fmt").WithCode("REGISTERED_AFTER").WithUserMessage("explicit").WithHTTPStatus(409)
+	assert.Equal(t, "explicit", err.UserMessage())
+	status, _ := err.HTTPStatus()
+	assert.Equal(t, 409, status)
+}
+
+func TestWithCode_DoesNotModifyOriginal(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithCode("CODE")
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, "CODE", cp.Code())
+}
+
+func TestWithCode_PropagatesThroughWrap(t *testing.T) {
+	xerror.RegisterCode("REGISTERED_WRAP", "user message", 404)
+	err := xerror.Wrap(xerror.New("fmt").WithCode("REGISTERED_WRAP"), "fmt2")
+	assert.Equal(t, "REGISTERED_WRAP", err.Code())
+	assert.Equal(t, "user message", err.UserMessage())
+	status, _ := err.HTTPStatus()
+	assert.Equal(t, 404, status)
+}
+
+func TestContainsCode(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(xerror.New("fmt").WithCode("INNER"), "fmt2"), "fmt3").WithCode("OUTER")
+	assert.Equal(t, "OUTER", err.Code())
+	assert.True(t, err.ContainsCode("OUTER"))
+	assert.True(t, err.ContainsCode("INNER"))
+	assert.False(t, err.ContainsCode("OTHER"))
+	assert.False(t, err.ContainsCode(""))
+}
+
+func TestContainsCode_Override(t *testing.T) {
+	err := xerror.New("fmt").WithCode("FIRST").WithCode("SECOND")
+	assert.Equal(t, "SECOND", err.Code())
+	assert.False(t, err.ContainsCode("FIRST"))
+}
+
+func TestContainsCode_TopLevel(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt").WithCode("INNER"), "fmt2")
+	assert.True(t, xerror.ContainsCode(err, "INNER"))
+	assert.False(t, xerror.ContainsCode(err, "OTHER"))
+	assert.False(t, xerror.ContainsCode(errors.New("INNER"), "INNER"))
+	assert.False(t, xerror.ContainsCode(nil, "INNER"))
+}
+
+func TestAllCodes(t *testing.T) {
+	xerror.RegisterCode("CATALOG_B", "b", 400)
+	xerror.RegisterKind("CATALOG_B", "Invalid")
+	xerror.RegisterKind("CATALOG_A", "NotFound")
+	xerror.RegisterCode("CATALOG_A", "a", 404)
+	xerror.RegisterCode("CATALOG_B", "b2", 422)
+
+	catalog := []xerror.CodeInfo{}
+	for _, info := range xerror.AllCodes() {
+		if strings.HasPrefix(info.Code, "CATALOG_") {
+			catalog = append(catalog, info)
+		}
+	}
+	assert.Equal(t, []xerror.CodeInfo{
+		{Code: "CATALOG_A", UserMessage: "a", HTTPStatus: 404, Kind: "NotFound"},
+		{Code: "CATALOG_B", UserMessage: "b2", HTTPStatus: 422, Kind: "Invalid"},
+	}, catalog)
+}
+
+func TestAllCodes_Sorted(t *testing.T) {
+	all := xerror.AllCodes()
+	assert.True(t, sort.SliceIsSorted(all, func(i, j int) bool { return all[i].Code < all[j].Code }))
+}
+
+func TestAllCodes_Concurrent(t *testing.T) {
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			xerror.RegisterCode(fmt.Sprintf("CONCURRENT_%v", i), "msg", 500)
+		}(i)
+		go func() {
+			defer wg.Done()
+			xerror.AllCodes()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestHTTPStatus_Wrap(t *testing.T) {
+	inner := xerror.New("fmt").WithHTTPStatus(404)
+	err := xerror.Wrap(xerror.Wrap(inner, "fmt2"), "fmt3")
+	status, ok := err.HTTPStatus()
+	assert.True(t, ok)
+	assert.Equal(t, 404, status)
+
+	status, ok = xerror.Wrap(err.WithHTTPStatus(409), "fmt4").HTTPStatus()
+	assert.True(t, ok)
+	assert.Equal(t, 409, status)
+
+	_, ok = xerror.Wrap(xerror.Wrap(errors.New("ew"), "fmt2"), "fmt3").HTTPStatus()
+	assert.False(t, ok)
+}
+
+func TestHTTPStatus_JSON(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt").WithHTTPStatus(404), "fmt2")
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(err.GoString()), &m))
+	assert.Equal(t, 404.0, m["http_status"])
+
+	e, jerr := xerror.UnmarshalJSON([]byte(err.GoString()))
+	assert.Nil(t, jerr)
+	status, ok := e.HTTPStatus()
+	assert.True(t, ok)
+	assert.Equal(t, 404, status)
+
+	m2 := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").GoString()), &m2))
+	_, ok = m2["http_status"]
+	assert.False(t, ok)
+}
+
+func TestHasCode(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt").WithCode("USER_NOT_FOUND"), "fmt2")
+	assert.True(t, xerror.HasCode(err, "USER_NOT_FOUND"))
+	assert.True(t, xerror.HasCode(fmt.Errorf("wrapped: %w", err), "USER_NOT_FOUND"))
+	assert.False(t, xerror.HasCode(err.WithCode("OTHER"), "USER_NOT_FOUND"))
+	assert.False(t, xerror.HasCode(xerror.New("fmt"), ""))
+	assert.False(t, xerror.HasCode(errors.New("USER_NOT_FOUND"), "USER_NOT_FOUND"))
+	assert.False(t, xerror.HasCode(nil, "USER_NOT_FOUND"))
+}
+
+func TestCode_JSON(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt").WithCode("USER_NOT_FOUND"), "fmt2")
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(err.GoString()), &m))
+	assert.Equal(t, "USER_NOT_FOUND", m["code"])
+
+	e, jerr := xerror.UnmarshalJSON([]byte(err.GoString()))
+	assert.Nil(t, jerr)
+	assert.Equal(t, "USER_NOT_FOUND", e.Code())
+	assert.True(t, e.ContainsCode("USER_NOT_FOUND"))
+
+	e, jerr = xerror.UnmarshalJSON([]byte(xerror.New("fmt").GoString()))
+	assert.Nil(t, jerr)
+	assert.Equal(t, "", e.Code())
+}
+
+func TestWithCode_Recode(t *testing.T) {
+	xerror.RegisterCode("RECODE_FIRST", "first", 404)
+	xerror.RegisterCode("RECODE_SECOND", "second", 409)
+	err := xerror.New("fmt").WithCode("RECODE_FIRST").WithCode("RECODE_SECOND")
+	assert.Equal(t, "second", err.UserMessage())
+	status, _ := err.HTTPStatus()
+	assert.Equal(t, 409, status)
+
+	err = err.WithCode("UNREGISTERED")
+	assert.Equal(t, "", err.UserMessage())
+	_, ok := err.HTTPStatus()
+	assert.False(t, ok)
+}
+
+func TestWithCode_RecodeExplicit(t *testing.T) {
+	xerror.RegisterCode("RECODE_EXPLICIT", "user message", 404)
+	err := xerror.New("fmt").WithUserMessage("explicit").WithHTTPStatus(500).WithCode("RECODE_EXPLICIT")
+	err = err.WithCode("UNREGISTERED")
+	assert.Equal(t, "explicit", err.UserMessage())
+	status, _ := err.HTTPStatus()
+	assert.Equal(t, 500, status)
+}
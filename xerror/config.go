@@ -0,0 +1,105 @@
+package xerror
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// config holds the package-level settings
+type config struct {
+	layerTimestamps     bool
+	messageRedactors    []messageRedactor
+	formatNormalizer    func(string) string
+	idGenerator         func() string
+	logger              func(Error)
+	jsonMaxDebugDepth   int
+	formatInterning     bool
+	duplicateWrapPolicy DuplicateWrapPolicy
+	templatePlaceholder string
+	debugSortKey        func(interface{}) string
+	development         bool
+	debugValueMaxLen    int
+	messageSeparator    string
+	frameEntryCapture   bool
+	maxStackDepth       int
+	exitFunc            func(int)
+	packageField        bool
+	maxCheckpoints      int
+	noStackCapture      bool
+	clock               func() time.Time
+	debugRedactor       func(interface{}) interface{}
+}
+
+var (
+	configMu sync.Mutex
+	cfg      atomic.Pointer[config]
+)
+
+// getConfig returns a snapshot of the package-level settings, which must not be modified. It doesn't copy the settings,
+// so that hot paths like `New` can read them several times cheaply.
+func getConfig() *config {
+	if c := cfg.Load(); c != nil {
+		return c
+	}
+	return &config{}
+}
+
+// setConfig applies the given change to a copy of the package-level settings, and publishes the copy
+func setConfig(fn func(*config)) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	c := *getConfig()
+	fn(&c)
+	cfg.Store(&c)
+}
+
+// SetProductionMode enables or disables production mode. In production mode, which is the default, stacks are omitted
+// from the representations of errors meant for clients or for flat log records: `GraphQLExtensions`, `SlogAttrs`, and
+// the responses written by `xhttp.Recover`. Disable it e.g. in development builds to include them. Representations
+// meant for internal use, such as `MarshalJSON` and `LogValue`, always include the stack.
+func SetProductionMode(enabled bool) {
+	setConfig(func(c *config) {
+		c.development = !enabled
+	})
+}
+
+// ProductionMode returns true if production mode is enabled (see `SetProductionMode`). It is meant for packages
+// rendering errors for clients, to decide whether to include the stack.
+func ProductionMode() bool {
+	return !getConfig().development
+}
+
+// SetLayerTimestamps enables or disables recording the time at which each layer (`New` and every `Wrap`) is created,
+// as returned by `LayerTimestamps`. It is disabled by default to avoid the cost of calling `time.Now` on every layer.
+func SetLayerTimestamps(enabled bool) {
+	setConfig(func(c *config) {
+		c.layerTimestamps = enabled
+	})
+}
+
+// SetFormatNormalizer sets a function applied to message formats when errors are created by `New` and `Wrap`, before
+// placeholders are counted and the message is rendered. The same function is applied to the formats passed to `Is` and
+// `Contains` (and the patterns passed to `IsGlob` and `ContainsGlob`), so that errors still match the original format
+// constants. Passing nil disables normalization, which is the default. See `NormalizeWhitespace` for a built-in
+// normalizer.
+func SetFormatNormalizer(fn func(string) string) {
+	setConfig(func(c *config) {
+		c.formatNormalizer = fn
+	})
+}
+
+// NormalizeWhitespace is a format normalizer that trims the format and collapses internal whitespace (including
+// newlines and indentation) into single spaces. It allows to use multi-line raw string literals as formats.
+func NormalizeWhitespace(format string) string {
+	return strings.Join(strings.Fields(format), " ")
+}
+
+// normalizeFormat applies the format normalizer, if any, to the given format
+func normalizeFormat(format string) string {
+	if fn := getConfig().formatNormalizer; fn != nil {
+		return fn(format)
+	}
+	return format
+}
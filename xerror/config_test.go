@@ -0,0 +1,44 @@
+package xerror_test
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const multiLineFormat = `
+	invalid value
+	for field %v
+`
+
+func TestSetFormatNormalizer_Disabled(t *testing.T) {
+	err := xerror.New(multiLineFormat, "userId")
+	assert.Equal(t, "\n\tinvalid value\n\tfor field userId\n", err.Error())
+	assert.True(t, xerror.Is(err, multiLineFormat))
+}
+
+func TestSetFormatNormalizer_NormalizeWhitespace(t *testing.T) {
+	xerror.SetFormatNormalizer(xerror.NormalizeWhitespace)
+	defer xerror.SetFormatNormalizer(nil)
+
+	err := xerror.Wrap(xerror.New(multiLineFormat, "userId", "d1"), "  bad \t request  ")
+	assert.Equal(t, "bad request: invalid value for field userId", err.Error())
+	assert.Equal(t, []interface{}{"userId", "d1"}, err.Debug())
+	assert.True(t, xerror.Is(err, "bad request"))
+	assert.True(t, xerror.Is(err, "  bad \t request  "))
+	assert.True(t, err.Contains(multiLineFormat))
+	assert.True(t, xerror.Contains(err, "invalid value for field %v"))
+}
+
+func TestNormalizeWhitespace(t *testing.T) {
+	assert.Equal(t, "a b c", xerror.NormalizeWhitespace("\n  a\tb \n c  "))
+	assert.Equal(t, "", xerror.NormalizeWhitespace(" \n "))
+}
+
+func TestSetProductionMode(t *testing.T) {
+	assert.True(t, xerror.ProductionMode())
+	xerror.SetProductionMode(false)
+	assert.False(t, xerror.ProductionMode())
+	xerror.SetProductionMode(true)
+	assert.True(t, xerror.ProductionMode())
+}
@@ -0,0 +1,48 @@
+package xerror
+
+import (
+	"time"
+)
+
+// SetClock sets the function returning the current time, used to timestamp errors (see `CreatedAt`, `WrappedAt`, and
+// `LayerTimestamps`) and to measure the elapsed time recorded by `WrapTimed`. It is intended primarily for tests, to
+// inject a deterministic clock. Passing nil restores the default, `time.Now`.
+func SetClock(fn func() time.Time) {
+	setConfig(func(c *config) {
+		c.clock = fn
+	})
+}
+
+// now returns the current time according to the clock set by `SetClock`
+func now() time.Time {
+	if clock := getConfig().clock; clock != nil {
+		return clock()
+	}
+	return time.Now()
+}
+
+// CreatedAt returns the time at which the error was created. Wrapping preserves the creation time of the wrapped error,
+// or uses the time of the wrap for Go errors.
+func (e *xerr) CreatedAt() time.Time {
+	return e.created
+}
+
+// WrappedAt returns the time at which the error was last wrapped (e.g. by `Wrap`), or the zero time if it was never
+// wrapped.
+func (e *xerr) WrappedAt() time.Time {
+	return e.wrapped
+}
+
+// formatTime formats the given time as RFC 3339 for JSON, or returns an empty string for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseTime parses a time formatted by `formatTime`, returning the zero time for an empty or invalid string
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
@@ -0,0 +1,71 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCreatedAt(t *testing.T) {
+	t1, t2 := time.Unix(1000, 0).UTC(), time.Unix(2000, 0).UTC()
+	xerror.SetClock(func() time.Time { return t1 })
+	defer xerror.SetClock(nil)
+
+	orig := xerror.New("fmt")
+	assert.Equal(t, t1, orig.CreatedAt())
+	assert.True(t, orig.WrappedAt().IsZero())
+
+	xerror.SetClock(func() time.Time { return t2 })
+	err := xerror.Wrap(orig, "fmt2")
+	assert.Equal(t, t1, err.CreatedAt())
+	assert.Equal(t, t2, err.WrappedAt())
+	assert.True(t, orig.WrappedAt().IsZero())
+
+	err = xerror.Wrap(errors.New("go"), "fmt")
+	assert.Equal(t, t2, err.CreatedAt())
+	assert.Equal(t, t2, err.WrappedAt())
+}
+
+func TestWrappedAt_WrapLazy(t *testing.T) {
+	t1, t2 := time.Unix(1000, 0).UTC(), time.Unix(2000, 0).UTC()
+	xerror.SetClock(func() time.Time { return t1 })
+	defer xerror.SetClock(nil)
+
+	orig := xerror.New("fmt")
+	xerror.SetClock(func() time.Time { return t2 })
+	err := xerror.WrapLazy(orig, func() string { return "lazy" })
+	assert.Equal(t, t1, err.CreatedAt())
+	assert.Equal(t, t2, err.WrappedAt())
+}
+
+func TestCreatedAt_DefaultClock(t *testing.T) {
+	before := time.Now()
+	err := xerror.New("fmt")
+	assert.False(t, err.CreatedAt().Before(before))
+	assert.False(t, err.CreatedAt().After(time.Now()))
+}
+
+func TestCreatedAt_JSON(t *testing.T) {
+	t1, t2 := time.Unix(1000, 0).UTC(), time.Unix(2000, 0).UTC()
+	xerror.SetClock(func() time.Time { return t1 })
+	defer xerror.SetClock(nil)
+
+	orig := xerror.New("fmt")
+	buf, e := json.Marshal(orig)
+	assert.Nil(t, e)
+	assert.Contains(t, string(buf), `"createdAt":"1970-01-01T00:16:40Z"`)
+	assert.NotContains(t, string(buf), `"wrappedAt"`)
+
+	xerror.SetClock(func() time.Time { return t2 })
+	buf, e = json.Marshal(xerror.Wrap(orig, "fmt2"))
+	assert.Nil(t, e)
+	assert.Contains(t, string(buf), `"wrappedAt":"1970-01-01T00:33:20Z"`)
+
+	err, e := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, e)
+	assert.True(t, t1.Equal(err.CreatedAt()))
+	assert.True(t, t2.Equal(err.WrappedAt()))
+}
@@ -0,0 +1,109 @@
+package xerror
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// truncatedValue replaces debug values nested deeper than the configured maximum depth
+const truncatedValue = "<truncated>"
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SetJSONMaxDebugDepth limits the nesting depth of debug objects serialized by `MarshalJSON`, `LogValue`, and the other
+// renderings using `RenderedDebug`: maps, slices, arrays and structs nested more than `n` levels deep (a debug object
+// itself being at level 1) are replaced by "<truncated>". To do so debug objects are walked with reflection before
+// marshaling, converting structs to maps of their exported fields keyed by JSON name. Values implementing
+// `json.Marshaler` or `encoding.TextMarshaler` are kept as they are. A value of 0, the default, means unlimited.
+func SetJSONMaxDebugDepth(n int) {
+	setConfig(func(c *config) {
+		c.jsonMaxDebugDepth = n
+	})
+}
+
+// limitDebugDepth returns the debug objects truncated to the configured maximum depth
+func limitDebugDepth(dbg []interface{}) []interface{} {
+	max := getConfig().jsonMaxDebugDepth
+	if max <= 0 {
+		return dbg
+	}
+	limited := make([]interface{}, 0, len(dbg))
+	for _, d := range dbg {
+		limited = append(limited, limitDepth(reflect.ValueOf(d), 1, max))
+	}
+	return limited
+}
+
+// limitDepth converts the given value, found at the given nesting level, truncating containers deeper than max
+func limitDepth(v reflect.Value, level, max int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return limitDepth(v.Elem(), level, max)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if level > max {
+			return truncatedValue
+		}
+		m := make(map[string]interface{}, v.Len())
+		for _, k := range v.MapKeys() {
+			m[fmt.Sprint(k.Interface())] = limitDepth(v.MapIndex(k), level+1, max)
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		if level > max {
+			return truncatedValue
+		}
+		s := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s = append(s, limitDepth(v.Index(i), level+1, max))
+		}
+		return s
+	case reflect.Struct:
+		if level > max {
+			return truncatedValue
+		}
+		m := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := f.Name
+			if tag := strings.Split(f.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			m[name] = limitDepth(v.Field(i), level+1, max)
+		}
+		return m
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+}
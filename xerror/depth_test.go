@@ -0,0 +1,65 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type nestedDebug struct {
+	Name   string      `json:"name"`
+	Child  interface{} `json:"child,omitempty"`
+	Hidden string      `json:"-"`
+	secret string
+}
+
+func marshalDebug(t *testing.T, err xerror.Error) interface{} {
+	buf, err2 := json.Marshal(err)
+	assert.Nil(t, err2)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	return m["debug"]
+}
+
+func deepMap(depth int) interface{} {
+	var v interface{} = "leaf"
+	for i := 0; i < depth; i++ {
+		v = map[string]interface{}{"k": v}
+	}
+	return v
+}
+
+func TestSetJSONMaxDebugDepth_Unlimited(t *testing.T) {
+	err := xerror.New("fmt", deepMap(5))
+	assert.Equal(t, []interface{}{deepMap(5)}, marshalDebug(t, err))
+}
+
+func TestSetJSONMaxDebugDepth_Maps(t *testing.T) {
+	xerror.SetJSONMaxDebugDepth(2)
+	defer xerror.SetJSONMaxDebugDepth(0)
+
+	err := xerror.New("fmt", deepMap(5), deepMap(2), "scalar", 1)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"k": map[string]interface{}{"k": "<truncated>"}},
+		deepMap(2),
+		"scalar",
+		float64(1),
+	}, marshalDebug(t, err))
+	assert.Equal(t, []interface{}{deepMap(5), deepMap(2), "scalar", 1}, err.Debug())
+}
+
+func TestSetJSONMaxDebugDepth_SlicesAndStructs(t *testing.T) {
+	xerror.SetJSONMaxDebugDepth(1)
+	defer xerror.SetJSONMaxDebugDepth(0)
+
+	d := &nestedDebug{Name: "n1", Child: &nestedDebug{Name: "n2"}, Hidden: "h", secret: "s"}
+	err := xerror.New("fmt", d, []interface{}{1, []int{2}}, []byte("raw"), time.Unix(0, 0).UTC())
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "n1", "child": "<truncated>"},
+		[]interface{}{float64(1), "<truncated>"},
+		"cmF3",
+		"1970-01-01T00:00:00Z",
+	}, marshalDebug(t, err))
+}
@@ -0,0 +1,64 @@
+package xerror
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DuplicateWrapPolicy tells `Wrap` how to handle wrapping an error whose outermost layer has the same message.
+type DuplicateWrapPolicy int
+
+// Known duplicate wrap policies.
+const (
+	// DuplicateWrapAllow adds the duplicate layer, e.g. "same message: same message". It is the default.
+	DuplicateWrapAllow DuplicateWrapPolicy = iota
+	// DuplicateWrapSkip returns a copy of the wrapped error, without adding the duplicate layer.
+	DuplicateWrapSkip
+	// DuplicateWrapCount collapses the duplicate layers into one with a count, e.g. "same message (x2)".
+	DuplicateWrapCount
+)
+
+// SetDuplicateWrapPolicy sets how `Wrap` handles wrapping an `Error` whose outermost layer has the same format and
+// renders to the same message as the new layer, which usually happens when an error is accidentally wrapped twice.
+// When a duplicate layer is skipped or collapsed, its debug objects are not added and the returned error keeps the
+// cause of the wrapped one.
+func SetDuplicateWrapPolicy(policy DuplicateWrapPolicy) {
+	setConfig(func(c *config) {
+		c.duplicateWrapPolicy = policy
+	})
+}
+
+// collapseDuplicateWrap applies the duplicate wrap policy to the given error, whose outermost layer has the same format
+// of the rendered `layer` being added, returning true if the layer must not be added
+func collapseDuplicateWrap(e *xerr, layer string) bool {
+	switch getConfig().duplicateWrapPolicy {
+	case DuplicateWrapSkip:
+		return e.layers[0] == layer
+	case DuplicateWrapCount:
+		n, ok := duplicateCount(e.layers[0], layer)
+		if !ok {
+			return false
+		}
+		e.layers[0] = fmt.Sprintf("%v (x%v)", layer, n+1)
+		e.msg = strings.Join(e.layers, DefaultSeparator)
+		return true
+	default:
+		return false
+	}
+}
+
+// duplicateCount returns how many times `layer` is repeated in `existing`, either bare or as collapsed by the count
+// policy, and false if `existing` is a different message
+func duplicateCount(existing, layer string) (int, bool) {
+	if existing == layer {
+		return 1, true
+	}
+	if !strings.HasPrefix(existing, layer+" (x") || !strings.HasSuffix(existing, ")") {
+		return 0, false
+	}
+	n := 0
+	if _, err := fmt.Sscanf(existing[len(layer):], " (x%d)", &n); err != nil || fmt.Sprintf("%v (x%v)", layer, n) != existing {
+		return 0, false
+	}
+	return n, true
+}
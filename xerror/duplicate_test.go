@@ -0,0 +1,51 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetDuplicateWrapPolicy_Allow(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(errors.New("ew"), "same %v", "p1"), "same %v", "p1")
+	assert.Equal(t, "same p1: same p1: ew", err.Error())
+	assert.Equal(t, []interface{}{"p1", "p1"}, err.Debug())
+}
+
+func TestSetDuplicateWrapPolicy_Skip(t *testing.T) {
+	xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapSkip)
+	defer xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapAllow)
+
+	inner := xerror.Wrap(errors.New("ew"), "same %v", "p1")
+	err := xerror.Wrap(inner, "same %v", "p1")
+	assert.Equal(t, "same p1: ew", err.Error())
+	assert.Equal(t, []interface{}{"p1"}, err.Debug())
+	assert.True(t, err != inner)
+
+	err = xerror.Wrap(inner, "same %v", "p2")
+	assert.Equal(t, "same p2: same p1: ew", err.Error())
+}
+
+func TestSetDuplicateWrapPolicy_Count(t *testing.T) {
+	xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapCount)
+	defer xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapAllow)
+
+	err := xerror.Wrap(errors.New("ew"), "same message")
+	err = xerror.Wrap(err, "same message")
+	assert.Equal(t, "same message (x2): ew", err.Error())
+	err = xerror.Wrap(err, "same message")
+	assert.Equal(t, "same message (x3): ew", err.Error())
+	assert.True(t, xerror.Is(err, "same message"))
+
+	err = xerror.Wrap(err, "other")
+	assert.Equal(t, "other: same message (x3): ew", err.Error())
+}
+
+func TestSetDuplicateWrapPolicy_CountNew(t *testing.T) {
+	xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapCount)
+	defer xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapAllow)
+
+	assert.Equal(t, "fmt (x2)", xerror.Wrap(xerror.New("fmt"), "fmt").Error())
+	assert.Equal(t, "fmt: fmt (x2)", xerror.Wrap(xerror.New("fmt (x2)"), "fmt").Error())
+}
@@ -6,73 +6,287 @@ information such as stack traces and debug values.
 package xerror
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"runtime/debug"
+	"io"
+	"log/slog"
 	"strings"
+	"time"
 )
 
 // Error is the augmented error interface provided by this package.
 type Error interface {
 	error
 	json.Marshaler
+	json.Unmarshaler
+	encoding.TextMarshaler
 	fmt.GoStringer
+	fmt.Formatter
+	slog.LogValuer
 
-	Is(string) bool
+	Is(error) bool
+	As(interface{}) bool
 	Contains(string) bool
+	Matches(string) bool
+	IsWellFormed() bool
+	IsGlob(string) bool
+	ContainsGlob(string) bool
 	Debug() []interface{}
+	DebugLen() int
+	RenderedDebug() []interface{}
+	WithMessages(...string) Error
+	WithDebug(...interface{}) Error
 	Stack() []string
 	Clone() Error
+	WithCode(string) Error
+	Code() string
+	Kind() Kind
+	WithUserMessage(string) Error
+	UserMessage() string
+	WithHTTPStatus(int) Error
+	HTTPStatus() (int, bool)
+	Messages() []string
+	MessageChain() []string
+	WithSeparator(string) Error
+	LayerTimestamps() []time.Time
+	WithField(string, interface{}) Error
+	WithFields(map[string]interface{}) Error
+	Fields() map[string]interface{}
+	WithPublicField(string, interface{}) Error
+	PublicFields() map[string]interface{}
+	WithDebugBlob(string, []byte, int) Error
+	WithRuntimeStats() Error
+	WithTemplate(string) Error
+	RenderTemplate() string
+	WithChildren(...error) Error
+	Children() []error
+	WalkTree(func(int, error))
+	StackFrames() []Frame
+	HasStack() bool
+	Materialize() Error
+	AppendStack([]uintptr) Error
+	GoldenString(string) string
+	EstimateJSONSize() int
+	Unwrap() error
+	Opaque() error
+	Reframe(string) Error
+	WithHelpURL(string) Error
+	HelpURL() string
+	WithSeverity(Severity) Error
+	Severity() Severity
+	AtLeastSeverity(Severity) bool
+	WithExitCode(int) Error
+	ExitCode() int
+	StackContainsFile(string) bool
+	InstanceID() string
+	WithInstanceID(string) Error
+	ID() string
+	WithID(string) Error
+	WithOperationKey(string) Error
+	OperationKey() string
+	NewOccurrence() Error
+	SlogAttrs() []slog.Attr
+	GraphQLExtensions() map[string]interface{}
+	MarkReported() Error
+	IsReported() bool
+	MarkExpected() Error
+	IsExpected() bool
+	LogLevel() string
+	WithSpanContext(SpanContext) Error
+	SpanContext() (SpanContext, bool)
+	ContainsCode(string) bool
+	WithRetryable(bool) Error
+	ShouldRetry(RetryPolicy, int) bool
+	WithCheckpoint(string, time.Time) Error
+	Checkpoints() []Checkpoint
+	CreatedAt() time.Time
+	WrappedAt() time.Time
 }
 
 // xerror is the internal implementation of Error
 type xerr struct {
-	msg   string
-	fmts  []string
-	dbg   []interface{}
-	stack []string
+	msg         string
+	fmts        []string
+	layers      []string
+	dbg         []interface{}
+	stack       *stackTrace
+	codes       []string
+	userMsg     string
+	httpStatus  int
+	times       []time.Time
+	fields      map[string]interface{}
+	cause       error
+	helpURL     string
+	severity    Severity
+	id          string
+	reported    bool
+	spanContext *SpanContext
+	lazy        []*lazyLayer
+	template    string
+	children    []error
+	combined    bool
+	expected    bool
+	kind        Kind
+	sep         string
+	opKey       string
+	pub         map[string]bool
+	exit        int
+	retry       *bool
+	checks      []Checkpoint
+	created     time.Time
+	wrapped     time.Time
 }
 
 // xerrorJSON is used to serialize Error to JSON
 type xerrJSON struct {
-	Message string        `json:"message"`
-	Debug   []interface{} `json:"debug,omitempty"`
-	Stack   []string      `json:"stack"`
+	Version     int                    `json:"schemaVersion"`
+	ID          string                 `json:"id,omitempty"`
+	Message     string                 `json:"message"`
+	Code        string                 `json:"code,omitempty"`
+	Formats     []string               `json:"formats,omitempty"`
+	Separator   string                 `json:"separator,omitempty"`
+	Combined    bool                   `json:"combined,omitempty"`
+	Debug       []interface{}          `json:"debug,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+	HelpURL     string                 `json:"helpUrl,omitempty"`
+	Severity    string                 `json:"severity,omitempty"`
+	HTTPStatus  int                    `json:"http_status,omitempty"`
+	Retryable   *bool                  `json:"retryable,omitempty"`
+	Checkpoints []Checkpoint           `json:"checkpoints,omitempty"`
+	CreatedAt   string                 `json:"createdAt,omitempty"`
+	WrappedAt   string                 `json:"wrappedAt,omitempty"`
+	OpKey       string                 `json:"operationKey,omitempty"`
+	SpanContext *SpanContext           `json:"spanContext,omitempty"`
+	Stack       []string               `json:"stack"`
+	Children    []interface{}          `json:"children,omitempty"`
 }
 
 // New returns a new augmented error. Parameters that don't have a placeholder in the format string are only stored as debug objects.
 func New(format string, v ...interface{}) Error {
 	v = nilToEmpty(v)
-	return &xerr{
-		msg:   safeSprintf(format, v),
-		fmts:  []string{format},
-		dbg:   v,
-		stack: strings.Split(string(debug.Stack()), "\n"),
-	}
+	format = internFormat(normalizeFormat(format))
+	return newXerr(format, safeSprintf(format, v), v, newStack())
+}
+
+// NewLiteral is like `New`, but stores the given message verbatim instead of formatting it, so that messages containing
+// "%" (e.g. "100% failure") are not mangled. The message is also the format matched by `Is` and `Contains`. All the
+// given parameters are stored as debug objects.
+func NewLiteral(msg string, debug ...interface{}) Error {
+	msg = internFormat(normalizeFormat(msg))
+	return newXerr(msg, msg, nilToEmpty(debug), newStack())
 }
 
-// Wrap returns a new augmented error that wraps the given Go `error` or `Error`.
+// Wrap returns a new augmented error that wraps the given Go `error` or `Error`. The wrapped error is retained and
+// returned by `Unwrap`, so that `errors.Is` and `errors.As` can traverse the chain.
 func Wrap(err error, format string, v ...interface{}) Error {
 	v = nilToEmpty(v)
+	format = internFormat(normalizeFormat(format))
+	layer := safeSprintf(format, v)
 	xerr := cloneOrNew(err)
-	xerr.msg = fmt.Sprintf("%v: %v", safeSprintf(format, v), xerr.msg)
+	if xerr.fmts[0] == format && !xerr.isLazy(0) && collapseDuplicateWrap(xerr, layer) {
+		return xerr
+	}
+	xerr.cause = err
+	xerr.msg = layer + DefaultSeparator + xerr.msg
 	xerr.fmts = append([]string{format}, xerr.fmts...)
+	xerr.layers = append([]string{layer}, xerr.layers...)
 	xerr.dbg = append(v, xerr.dbg...)
+	xerr.times = append([]time.Time{layerTime()}, xerr.times...)
+	xerr.codes = append([]string{""}, xerr.codes...)
+	xerr.wrapped = now()
+	xerr.prependEagerLayers(1)
 	return xerr
 }
 
+// NewE is like `New`, but returns the error as a Go `error`. Use it in functions whose signatures return `error`, and
+// `New` when callers need the `Error` methods without a type assertion.
+func NewE(format string, v ...interface{}) error {
+	return New(format, v...)
+}
+
+// WrapE is like `Wrap`, but returns the error as a Go `error`. Use it in functions whose signatures return `error`, and
+// `Wrap` when callers need the `Error` methods without a type assertion.
+func WrapE(err error, format string, v ...interface{}) error {
+	return Wrap(err, format, v...)
+}
+
+// WrapIf is like `Wrap`, but returns nil if `err` is nil, e.g. for `return xerror.WrapIf(doThing(), "thing failed")`.
+func WrapIf(err error, format string, v ...interface{}) Error {
+	if err == nil {
+		return nil
+	}
+	return Wrap(err, format, v...)
+}
+
+// WrapReturn is like `WrapIf`, but also returns whether `err` is not nil. It is optional sugar for the early return
+// idiom, e.g. `if e, ok := xerror.WrapReturn(doThing(), "thing failed"); ok { return e }`.
+func WrapReturn(err error, format string, v ...interface{}) (Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	return Wrap(err, format, v...), true
+}
+
+// WrapEach wraps each non-nil error in the given slice with the same message format and parameters, as `Wrap` would.
+// The returned slice has the same length as `errs`, and nil errors are left as nil at their original positions.
+func WrapEach(format string, errs []error, v ...interface{}) []error {
+	wrapped := make([]error, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			wrapped[i] = Wrap(err, format, append([]interface{}(nil), v...)...)
+		}
+	}
+	return wrapped
+}
+
 // Error implements the `error` interface.
 func (e *xerr) Error() string {
-	return e.msg
+	return redactMessage(e.message())
 }
 
 // MarshalJSON implements the `json.Marshaler` interface.
 func (e *xerr) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&xerrJSON{
-		Message: e.msg,
-		Debug:   e.dbg,
-		Stack:   e.stack,
-	})
+	return json.Marshal(e.toJSON(map[*xerr]bool{}))
+}
+
+// MarshalText implements the `encoding.TextMarshaler` interface, returning the same (redacted) message as `Error`, e.g.
+// so that errors embedded in structs serialize to their message in YAML, TOML, or URL-encoded forms.
+func (e *xerr) MarshalText() ([]byte, error) {
+	return []byte(e.Error()), nil
+}
+
+// toJSON returns the JSON representation of the error, skipping the children of errors in `path` to guard against
+// cycles
+func (e *xerr) toJSON(path map[*xerr]bool) *xerrJSON {
+	j := &xerrJSON{
+		Version:     JSONSchemaVersion,
+		ID:          e.id,
+		Message:     e.Error(),
+		Code:        e.Code(),
+		Formats:     e.fmts,
+		Separator:   e.sep,
+		Combined:    e.combined,
+		Debug:       e.RenderedDebug(),
+		Fields:      e.fields,
+		HelpURL:     e.helpURL,
+		Severity:    e.Severity().String(),
+		HTTPStatus:  e.httpStatus,
+		Retryable:   e.retry,
+		Checkpoints: e.checks,
+		CreatedAt:   formatTime(e.created),
+		WrappedAt:   formatTime(e.wrapped),
+		OpKey:       e.opKey,
+		SpanContext: e.spanContext,
+		Stack:       e.Stack(),
+	}
+	if !path[e] {
+		path[e] = true
+		j.Children = childrenJSON(e.children, path)
+		delete(path, e)
+	}
+	return j
 }
 
 // GoString implements the `fmt.GoStringer` interface.
@@ -84,13 +298,66 @@ func (e *xerr) GoString() string {
 	return string(buf)
 }
 
-// Is returns true if the outermost error message format equals the given message format, false otherwise.
-func (e *xerr) Is(fmt string) bool {
-	return e.fmts[0] == fmt
+// Format implements the `fmt.Formatter` interface. The "%s" and "%v" verbs print the message, "%q" prints the quoted
+// message, "%+v" prints the message followed by the stack (one frame per line, indented with a tab), and "%#v" prints
+// the same JSON representation as `GoString`.
+func (e *xerr) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('#'):
+		io.WriteString(s, e.GoString())
+	case verb == 'v' && s.Flag('+'):
+		io.WriteString(s, e.Error())
+		for _, frame := range e.Stack() {
+			io.WriteString(s, "\n\t"+frame)
+		}
+	case verb == 'v' || verb == 's':
+		io.WriteString(s, e.Error())
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%s)", verb, e.Error())
+	}
+}
+
+// Is returns true if `target` is an `Error` with the same message formats, or if a child of the error (see `Children`)
+// matches `target` as `errors.Is` would, false otherwise. It makes sentinel errors created by `New` work with
+// `errors.Is`, which calls it for each error in the chain: for example, given `var ErrNotFound = xerror.New("not
+// found")`, `errors.Is(xerror.Wrap(xerror.New("not found"), "fmt"), ErrNotFound)` is true. Unlike the package-level
+// `Is`, which compares the outermost format with a format string, it compares errors. Errors created by `Combine` or
+// `Join`, and errors wrapping them, only match through their children, since they all share the `ErrorCombined` format.
+func (e *xerr) Is(target error) bool {
+	if x, ok := target.(*xerr); ok && !e.combined && equalFormats(e.fmts, x.fmts) {
+		return true
+	}
+	for _, child := range e.children {
+		if errors.Is(child, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalFormats returns true if the given message formats are the same
+func equalFormats(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isFormat returns true if the outermost error message format equals the given message format, false otherwise
+func (e *xerr) isFormat(format string) bool {
+	return e.fmts[0] == normalizeFormat(format)
 }
 
 // Contains returns true if the error contains the given message format, false otherwise.
 func (e *xerr) Contains(format string) bool {
+	format = normalizeFormat(format)
 	for _, f := range e.fmts {
 		if f == format {
 			return true
@@ -99,33 +366,120 @@ func (e *xerr) Contains(format string) bool {
 	return false
 }
 
+// Matches returns true if the given string equals either the outermost message format or the rendered message of the
+// outermost layer, false otherwise. The format is compared first, as the package-level `Is` does; then the rendered
+// message is, without redaction. It is meant for callers that don't know whether they hold a format or a rendered
+// message.
+func (e *xerr) Matches(s string) bool {
+	return e.isFormat(s) || e.renderedLayers()[0] == s
+}
+
 // Debug returns the slice of debug objects.
 func (e *xerr) Debug() []interface{} {
 	return e.dbg
 }
 
-// Stack returns the stack trace associated with the error.
-func (e *xerr) Stack() []string {
-	return e.stack
+// DebugLen returns the number of debug objects, without retrieving them.
+func (e *xerr) DebugLen() int {
+	return len(e.dbg)
+}
+
+// WithMessages returns a copy of the `Error` with the given message formats prepended as new layers, in the given
+// order: `err.WithMessages("a", "b")` has message "a: b: " followed by the message of `err`, like wrapping it with "b"
+// and then with "a". The formats take no parameters.
+func (e *xerr) WithMessages(formats ...string) Error {
+	x := e.Clone().(*xerr)
+	n := len(formats)
+	fmts := make([]string, 0, n+len(x.fmts))
+	layers := make([]string, 0, n+len(x.layers))
+	times := make([]time.Time, 0, n+len(x.times))
+	codes := make([]string, 0, n+len(x.codes))
+	for _, format := range formats {
+		format = internFormat(normalizeFormat(format))
+		fmts = append(fmts, format)
+		layers = append(layers, safeSprintf(format, nil))
+		times = append(times, layerTime())
+		codes = append(codes, "")
+	}
+	x.fmts = append(fmts, x.fmts...)
+	x.layers = append(layers, x.layers...)
+	x.times = append(times, x.times...)
+	x.codes = append(codes, x.codes...)
+	x.prependEagerLayers(n)
+	x.msg = strings.Join(x.layers, DefaultSeparator)
+	x.wrapped = now()
+	return x
+}
+
+// WithDebug returns a copy of the `Error` with the given debug objects appended.
+func (e *xerr) WithDebug(v ...interface{}) Error {
+	x := e.Clone().(*xerr)
+	x.dbg = append(x.dbg, v...)
+	return x
+}
+
+// Messages returns a copy of the message formats of the error, outermost first, e.g. to render each layer separately or
+// to assert on a specific layer in tests. See `MessageChain` for the rendered messages.
+func (e *xerr) Messages() []string {
+	return append(make([]string, 0, len(e.fmts)), e.fmts...)
+}
+
+// MessageChain returns the rendered message of each layer, outermost first, matching the order of the message formats.
+// Joining them with the separator (see `SetMessageSeparator`) yields `Error()` (before redaction).
+func (e *xerr) MessageChain() []string {
+	return e.renderedLayers()
+}
+
+// LayerTimestamps returns the times at which each layer was created, outermost first, matching the order of the
+// message formats. Times are zero for layers created while `SetLayerTimestamps` was disabled.
+func (e *xerr) LayerTimestamps() []time.Time {
+	return append(make([]time.Time, 0, len(e.times)), e.times...)
 }
 
 // Clone returns an exact copy of the `Error`.
 func (e *xerr) Clone() Error {
 	return &xerr{
-		msg:   e.msg,
-		fmts:  append(make([]string, 0, len(e.fmts)), e.fmts...),
-		dbg:   append(make([]interface{}, 0, len(e.dbg)), e.dbg...),
-		stack: append(make([]string, 0, len(e.stack)), e.stack...),
+		msg:         e.msg,
+		fmts:        append(make([]string, 0, len(e.fmts)), e.fmts...),
+		layers:      append(make([]string, 0, len(e.layers)), e.layers...),
+		dbg:         append(make([]interface{}, 0, len(e.dbg)), e.dbg...),
+		stack:       e.stack,
+		codes:       append(make([]string, 0, len(e.codes)), e.codes...),
+		userMsg:     e.userMsg,
+		httpStatus:  e.httpStatus,
+		times:       append(make([]time.Time, 0, len(e.times)), e.times...),
+		fields:      e.cloneFields(),
+		cause:       e.cause,
+		helpURL:     e.helpURL,
+		severity:    e.severity,
+		id:          e.id,
+		reported:    e.reported,
+		spanContext: e.spanContext,
+		lazy:        e.cloneLazy(),
+		template:    e.template,
+		children:    append([]error(nil), e.children...),
+		combined:    e.combined,
+		expected:    e.expected,
+		kind:        e.kind,
+		sep:         e.sep,
+		opKey:       e.opKey,
+		pub:         e.pub,
+		exit:        e.exit,
+		retry:       e.retry,
+		checks:      append([]Checkpoint(nil), e.checks...),
+		created:     e.created,
+		wrapped:     e.wrapped,
 	}
 }
 
 // Is returns true if the outermost message format (if `err` is `Error`) or error string (if `err` is a Go `error`) equals the given message.
+// To compare an error with a sentinel `Error` through its whole chain, use `errors.Is` instead.
 func Is(err error, format string) bool {
 	if err == nil {
 		return false
 	}
 	if xerr, ok := err.(*xerr); ok {
-		return xerr.Is(format)
+		return xerr.isFormat(format)
 	}
 	return err.Error() == format
 }
@@ -141,12 +495,82 @@ func Contains(err error, format string) bool {
 	return err.Error() == format
 }
 
+// Matches is like Is, but in case `err` is of type `Error` also compares the rendered message of the outermost layer
+// (see `Error.Matches`).
+func Matches(err error, s string) bool {
+	if err == nil {
+		return false
+	}
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.Matches(s)
+	}
+	return err.Error() == s
+}
+
+// FormatsDiff returns the message formats that appear in exactly one of the given errors: first those only in `a`, then
+// those only in `b`. A Go `error` is treated as having its error string as its only format. It is meant as a diagnostic
+// helper to understand why two errors are classified differently.
+func FormatsDiff(a, b error) []string {
+	fa, fb := formats(a), formats(b)
+	diff := []string{}
+	for _, f := range fa {
+		if !containsString(fb, f) && !containsString(diff, f) {
+			diff = append(diff, f)
+		}
+	}
+	for _, f := range fb {
+		if !containsString(fa, f) && !containsString(diff, f) {
+			diff = append(diff, f)
+		}
+	}
+	return diff
+}
+
+// formats returns the message formats of the given `error`, its error string if it is a Go `error`, or nil if nil
+func formats(err error) []string {
+	if err == nil {
+		return nil
+	}
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.fmts
+	}
+	return []string{err.Error()}
+}
+
+// containsString returns true if the given slice contains the given string
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// newXerr returns a new `*xerr` with the given (already normalized) message format, rendered message, debug objects,
+// and stack
+func newXerr(format, msg string, dbg []interface{}, stack *stackTrace) *xerr {
+	x := &xerr{
+		msg:    msg,
+		fmts:   []string{format},
+		layers: []string{msg},
+		dbg:    dbg,
+		stack:  stack,
+		times:  []time.Time{layerTime()},
+		codes:  []string{""},
+		id:     newInstanceID(),
+	}
+	x.created = now()
+	x.tagPackage()
+	return x
+}
+
 // cloneOrNew wraps the given `error` unless it is already of type `*xerror`, in which case it returns a copy
 func cloneOrNew(err error) *xerr {
 	if x, ok := err.(*xerr); ok {
 		return x.Clone().(*xerr)
 	}
-	return New(err.Error()).(*xerr)
+	return NewLiteral(err.Error()).(*xerr)
 }
 
 // safeSprintf is like `fmt.Sprintf`, but passes through only at most parameters as placeholders in the format string
@@ -157,6 +581,14 @@ func safeSprintf(format string, v []interface{}) string {
 	return fmt.Sprintf(format, v...)
 }
 
+// layerTime returns the current time if layer timestamps are enabled, the zero time otherwise
+func layerTime() time.Time {
+	if getConfig().layerTimestamps {
+		return now()
+	}
+	return time.Time{}
+}
+
 // nilToEmpty returns the given slice if not nil, or an empty slice if nil
 func nilToEmpty(v []interface{}) []interface{} {
 	if v == nil {
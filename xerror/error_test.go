@@ -1,12 +1,16 @@
 package xerror_test
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/ibrt/go-xerror/xerror"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNew_NoPlaceholdersAndNoDebug(t *testing.T) {
@@ -51,10 +55,47 @@ func TestNew_NotEnoughRequiredPlaceholders(t *testing.T) {
 	assert.True(t, len(err.Stack()) > 0)
 }
 
+func TestNewLiteral(t *testing.T) {
+	err := xerror.NewLiteral("100% failure", "d1", 2)
+	assert.Equal(t, "100% failure", err.Error())
+	assert.Equal(t, []interface{}{"d1", 2}, err.Debug())
+	assert.True(t, xerror.Is(err, "100% failure"))
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestNewLiteral", err.StackFrames()[0].Function)
+	assert.Equal(t, "fmt: 100% failure", xerror.Wrap(err, "fmt").Error())
+	assert.Equal(t, "100%!f(MISSING)ailure", xerror.New("100% failure").Error())
+}
+
+func TestNewLiteral_NoDebug(t *testing.T) {
+	err := xerror.NewLiteral("%v")
+	assert.Equal(t, "%v", err.Error())
+	assert.Equal(t, []interface{}{}, err.Debug())
+}
+
 func TestWrap_NilErr(t *testing.T) {
 	assert.Panics(t, func() { xerror.Wrap(nil, "fmt") })
 }
 
+func TestWrapIf(t *testing.T) {
+	err := xerror.WrapIf(errors.New("ew"), "fmt %v", "p1")
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.Equal(t, []interface{}{"p1"}, err.Debug())
+	assert.Nil(t, xerror.WrapIf(nil, "fmt %v", "p1"))
+
+	var e error = xerror.WrapIf(nil, "fmt")
+	assert.True(t, e == nil)
+}
+
+func TestWrapReturn(t *testing.T) {
+	err, ok := xerror.WrapReturn(errors.New("ew"), "fmt %v", "p1")
+	assert.True(t, ok)
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.Equal(t, []interface{}{"p1"}, err.Debug())
+
+	err, ok = xerror.WrapReturn(nil, "fmt %v", "p1")
+	assert.False(t, ok)
+	assert.Nil(t, err)
+}
+
 func TestWrap_NativeErrNoPlaceholdersAndNoDebug(t *testing.T) {
 	err := xerror.Wrap(errors.New("ew"), "fmt")
 	assert.Equal(t, "fmt: ew", err.Error())
@@ -83,6 +124,13 @@ func TestWrap_NativeErrPlaceholdersAndDebug(t *testing.T) {
 	assert.True(t, len(err.Stack()) > 0)
 }
 
+func TestWrap_NativeErrPercent(t *testing.T) {
+	err := xerror.Wrap(errors.New("100% failure: %d"), "fmt")
+	assert.Equal(t, "fmt: 100% failure: %d", err.Error())
+	assert.Equal(t, []interface{}{}, err.Debug())
+	assert.True(t, xerror.Contains(err, "100% failure: %d"))
+}
+
 func TestWrap_ErrorNoPlaceholdersAndNoDebug(t *testing.T) {
 	err := xerror.Wrap(xerror.New("fmt %v", "p1", "d1"), "fmt2")
 	assert.Equal(t, "fmt2: fmt p1", err.Error())
@@ -111,13 +159,14 @@ func TestWrap_ErrorPlaceholdersAndDebug(t *testing.T) {
 	assert.True(t, len(err.Stack()) > 0)
 }
 
-func TestIs_Method(t *testing.T) {
-	err := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2 %v", "p2")
-	assert.Equal(t, "fmt2 p2: fmt p1", err.Error())
-	assert.True(t, err.Is("fmt2 %v"))
-	assert.False(t, err.Is("fmt2 p2"))
-	assert.False(t, err.Is("fmt %v"))
-	assert.False(t, err.Is("fmt p1"))
+func TestIs_Sentinel(t *testing.T) {
+	sentinel := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2 %v", "p2")
+	assert.True(t, sentinel.Is(sentinel))
+	assert.True(t, xerror.Wrap(xerror.New("fmt %v", "p3"), "fmt2 %v", "p4").Is(sentinel))
+	assert.False(t, xerror.New("fmt2 %v", "p2").Is(sentinel))
+	assert.False(t, xerror.Wrap(sentinel, "fmt3").Is(sentinel))
+	assert.False(t, sentinel.Is(errors.New("fmt2 p2: fmt p1")))
+	assert.False(t, sentinel.Is(nil))
 }
 
 func TestIs_TopLevelNilErr(t *testing.T) {
@@ -167,6 +216,23 @@ func TestContains_TopLevelError(t *testing.T) {
 	assert.False(t, xerror.Contains(err, "fmt p1"))
 }
 
+func TestMatches_Method(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2 %v", "p2")
+	assert.True(t, err.Matches("fmt2 %v"))
+	assert.True(t, err.Matches("fmt2 p2"))
+	assert.False(t, err.Matches("fmt2 p2: fmt p1"))
+	assert.False(t, err.Matches("fmt %v"))
+	assert.False(t, err.Matches("fmt p1"))
+}
+
+func TestMatches_TopLevel(t *testing.T) {
+	assert.False(t, xerror.Matches(nil, ""))
+	assert.True(t, xerror.Matches(errors.New("ew"), "ew"))
+	assert.False(t, xerror.Matches(errors.New("ew"), "ew2"))
+	assert.True(t, xerror.Matches(xerror.New("fmt %v", "p1"), "fmt p1"))
+	assert.True(t, xerror.Matches(xerror.New("fmt %v", "p1"), "fmt %v"))
+}
+
 func TestClone_FormatOnly(t *testing.T) {
 	err := xerror.New("fmt")
 	cp := err.Clone()
@@ -194,6 +260,17 @@ func TestImplementsJSONMarshaler(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestImplementsTextMarshaler(t *testing.T) {
+	var m encoding.TextMarshaler = xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2")
+	buf, err := m.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, "fmt2: fmt p1", string(buf))
+
+	buf, err = json.Marshal(map[error]int{xerror.New("fmt"): 1})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"fmt":1}`, string(buf))
+}
+
 func TestImplementsFMTStringer(t *testing.T) {
 	err := xerror.New("fmt %v", "p1", "d1")
 	assert.Equal(t, "fmt p1", fmt.Sprintf("%v", error(err)))
@@ -201,3 +278,163 @@ func TestImplementsFMTStringer(t *testing.T) {
 	assert.Nil(t, err2)
 	assert.Equal(t, string(buf), fmt.Sprintf("%#v", error(err)))
 }
+
+func TestFormat(t *testing.T) {
+	err := xerror.Wrap(errors.New("ew"), "fmt %v", "p1")
+	assert.Equal(t, "fmt p1: ew", fmt.Sprintf("%v", err))
+	assert.Equal(t, "fmt p1: ew", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"fmt p1: ew"`, fmt.Sprintf("%q", err))
+	assert.Equal(t, err.GoString(), fmt.Sprintf("%#v", err))
+	assert.Equal(t, "fmt p1: ew\n\t"+strings.Join(err.Stack(), "\n\t"), fmt.Sprintf("%+v", err))
+	assert.Equal(t, "%!d(fmt p1: ew)", fmt.Sprintf("%d", err))
+}
+
+func TestFormatsDiff_Equal(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2 %v", "p2")
+	assert.Equal(t, []string{}, xerror.FormatsDiff(err, err.Clone()))
+}
+
+func TestFormatsDiff_Errors(t *testing.T) {
+	a := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt2 %v", "p2")
+	b := xerror.Wrap(xerror.New("fmt %v", "p1"), "fmt3")
+	assert.Equal(t, []string{"fmt2 %v", "fmt3"}, xerror.FormatsDiff(a, b))
+}
+
+func TestFormatsDiff_NativeErr(t *testing.T) {
+	a := xerror.Wrap(errors.New("ew"), "fmt")
+	assert.Equal(t, []string{"fmt", "ew", "other"}, xerror.FormatsDiff(a, errors.New("other")))
+	assert.Equal(t, []string{"fmt"}, xerror.FormatsDiff(a, errors.New("ew")))
+}
+
+func TestFormatsDiff_NilErr(t *testing.T) {
+	assert.Equal(t, []string{"fmt"}, xerror.FormatsDiff(xerror.New("fmt"), nil))
+	assert.Equal(t, []string{}, xerror.FormatsDiff(nil, nil))
+}
+
+func TestLayerTimestamps_Disabled(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt"), "fmt2")
+	assert.Equal(t, []time.Time{{}, {}}, err.LayerTimestamps())
+}
+
+func TestLayerTimestamps_Enabled(t *testing.T) {
+	xerror.SetLayerTimestamps(true)
+	defer xerror.SetLayerTimestamps(false)
+
+	start := time.Now()
+	err := xerror.New("fmt")
+	time.Sleep(10 * time.Millisecond)
+	err = xerror.Wrap(err, "fmt2")
+	ts := err.LayerTimestamps()
+	assert.Equal(t, 2, len(ts))
+	assert.False(t, ts[1].Before(start))
+	assert.True(t, ts[0].Sub(ts[1]) >= 10*time.Millisecond)
+}
+
+func TestNewE(t *testing.T) {
+	err := xerror.NewE("fmt %v", "p1", "d1")
+	assert.Equal(t, "fmt p1", err.Error())
+	xerr, ok := err.(xerror.Error)
+	assert.True(t, ok)
+	assert.Equal(t, []interface{}{"p1", "d1"}, xerr.Debug())
+}
+
+func TestWrapE(t *testing.T) {
+	err := xerror.WrapE(errors.New("ew"), "fmt %v", "p1")
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.True(t, xerror.Is(err, "fmt %v"))
+	_, ok := err.(xerror.Error)
+	assert.True(t, ok)
+}
+
+func TestWrapEach(t *testing.T) {
+	errs := xerror.WrapEach("batch %v", []error{errors.New("e1"), nil, xerror.New("e2")}, "b1", "d1")
+	assert.Len(t, errs, 3)
+	assert.Equal(t, "batch b1: e1", errs[0].Error())
+	assert.Nil(t, errs[1])
+	assert.Equal(t, "batch b1: e2", errs[2].Error())
+	assert.True(t, xerror.Is(errs[2], "batch %v"))
+	assert.True(t, xerror.Contains(errs[2], "e2"))
+	assert.Equal(t, []interface{}{"b1", "d1"}, errs[0].(xerror.Error).Debug())
+}
+
+func TestWrapEach_DebugNotShared(t *testing.T) {
+	v := make([]interface{}, 2, 10)
+	v[0], v[1] = "b1", "d1"
+	errs := xerror.WrapEach("batch %v", []error{errors.New("e1"), errors.New("e2")}, v...)
+	errs[0].(xerror.Error).Debug()[1] = "changed"
+	assert.Equal(t, []interface{}{"b1", "d1"}, errs[1].(xerror.Error).Debug())
+	assert.Equal(t, []interface{}{"b1", "d1"}, v)
+}
+
+func TestWrapEach_Empty(t *testing.T) {
+	assert.Empty(t, xerror.WrapEach("fmt", nil))
+	assert.Equal(t, []error{nil, nil}, xerror.WrapEach("fmt", []error{nil, nil}))
+}
+
+func TestWithMessages(t *testing.T) {
+	err := xerror.Wrap(errors.New("ew"), "fmt %v", "p1")
+	cp := err.WithMessages("unable to execute Method", "unable to decode")
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.Equal(t, "unable to execute Method: unable to decode: fmt p1: ew", cp.Error())
+	assert.True(t, xerror.Is(cp, "unable to execute Method"))
+	assert.True(t, cp.Contains("unable to decode"))
+	assert.True(t, cp.Contains("fmt %v"))
+	assert.False(t, err.Contains("unable to decode"))
+	assert.Equal(t, []string{"unable to execute Method", "unable to decode", "fmt p1", "ew"}, cp.MessageChain())
+	assert.Len(t, cp.LayerTimestamps(), 4)
+	assert.Equal(t, xerror.Wrap(xerror.Wrap(err, "unable to decode"), "unable to execute Method").Error(), cp.Error())
+}
+
+func TestWithMessages_NoFormats(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, "fmt", err.WithMessages().Error())
+}
+
+func TestDebugLen(t *testing.T) {
+	assert.Equal(t, 0, xerror.New("fmt").DebugLen())
+	assert.Equal(t, 2, xerror.New("fmt %v", "p1", "d1").DebugLen())
+	assert.Equal(t, 3, xerror.Wrap(xerror.New("fmt %v", "p1", "d1"), "fmt2", "d2").DebugLen())
+}
+
+func TestWithDebug(t *testing.T) {
+	err := xerror.New("fmt %v", "p1", "d1")
+	cp := err.WithDebug("d2", 3)
+	assert.Equal(t, []interface{}{"p1", "d1"}, err.Debug())
+	assert.Equal(t, []interface{}{"p1", "d1", "d2", 3}, cp.Debug())
+	assert.Equal(t, "fmt p1", cp.Error())
+}
+
+func TestMessages(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(errors.New("ew"), "fmt %v", "p1"), "fmt2")
+	assert.Equal(t, []string{"fmt2", "fmt %v", "ew"}, err.Messages())
+	err.Messages()[0] = "changed"
+	assert.True(t, xerror.Is(err, "fmt2"))
+	assert.Equal(t, []string{"fmt"}, xerror.New("fmt").Messages())
+}
+
+func TestMessageChain(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(errors.New("unexpected token"), "parsing %v", "json", "d1"), "reading config")
+	assert.Equal(t, []string{"reading config", "parsing json", "unexpected token"}, err.MessageChain())
+	assert.Equal(t, strings.Join(err.MessageChain(), ": "), err.Error())
+
+	chain := err.MessageChain()
+	chain[0] = "changed"
+	assert.Equal(t, "reading config", err.MessageChain()[0])
+}
+
+func TestMessageChain_New(t *testing.T) {
+	assert.Equal(t, []string{"fmt p1"}, xerror.New("fmt %v", "p1").MessageChain())
+}
+
+func TestError_IsSentinel(t *testing.T) {
+	sentinel := xerror.New("not found")
+	err := xerror.Wrap(xerror.New("not found"), "fmt2 %v", "p1")
+	assert.True(t, errors.Is(err, sentinel))
+	assert.True(t, errors.Is(xerror.Wrap(sentinel, "fmt2"), sentinel))
+	assert.True(t, xerror.New("not found").Is(sentinel))
+	assert.False(t, err.Is(sentinel))
+	assert.False(t, errors.Is(xerror.New("other"), sentinel))
+	assert.False(t, errors.Is(err, xerror.Wrap(xerror.New("not found"), "fmt3")))
+	assert.False(t, errors.Is(xerror.New("not found"), errors.New("not found")))
+	assert.True(t, errors.Is(xerror.Wrap(io.EOF, "fmt2"), io.EOF))
+}
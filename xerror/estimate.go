@@ -0,0 +1,52 @@
+package xerror
+
+import (
+	"fmt"
+)
+
+// jsonKeyOverhead approximates the size of the JSON keys and punctuation of the top-level object
+const jsonKeyOverhead = 64
+
+// EstimateJSONSize returns an estimate of the size in bytes of the JSON representation of the error, as produced by
+// `MarshalJSON`, without marshaling it, e.g. to decide whether to truncate an error before logging it. It accounts for
+// the message, the message formats, the stack frames, the debug objects, and the fields. It is an estimate: escaping
+// is ignored, numbers are counted by their decimal length, and other values (e.g. structs) by the length of their "%v"
+// form, so the actual size can differ, although the estimate is deterministic for a given error.
+func (e *xerr) EstimateJSONSize() int {
+	n := jsonKeyOverhead + len(e.id) + len(e.Error()) + 2
+	for _, f := range e.fmts {
+		n += len(f) + 3
+	}
+	for _, f := range e.frames() {
+		n += len(f.String()) + 3
+	}
+	for _, d := range e.dbg {
+		n += estimateValueSize(d) + 1
+	}
+	for k, v := range e.fields {
+		n += len(k) + 4 + estimateValueSize(v)
+	}
+	return n
+}
+
+// estimateValueSize returns an estimate of the size in bytes of the JSON representation of the given value
+func estimateValueSize(v interface{}) int {
+	switch t := v.(type) {
+	case nil:
+		return 4
+	case bool:
+		return 5
+	case string:
+		return len(t) + 2
+	case []byte:
+		return (len(t)+2)/3*4 + 2
+	case error:
+		return len(t.Error()) + 2
+	case fmt.Stringer:
+		return len(t.String()) + 2
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return len(fmt.Sprintf("%v", t))
+	default:
+		return len(fmt.Sprintf("%v", t)) + 2
+	}
+}
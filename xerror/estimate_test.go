@@ -0,0 +1,31 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func assertEstimate(t *testing.T, err xerror.Error) {
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	estimate := err.EstimateJSONSize()
+	assert.True(t, estimate > len(buf)/2 && estimate < len(buf)*2, "estimate %v, actual %v", estimate, len(buf))
+}
+
+func TestEstimateJSONSize(t *testing.T) {
+	assertEstimate(t, xerror.New("fmt"))
+	assertEstimate(t, xerror.Wrap(io.EOF, "fmt %v", "p1", 42, 1.5, true, nil, []int{1, 2, 3}))
+	assertEstimate(t, xerror.New("fmt").WithFields(map[string]interface{}{"k1": "v1", "k2": map[string]int{"a": 1}}))
+	assertEstimate(t, xerror.New("fmt", strings.Repeat("a", 10000)))
+	assertEstimate(t, xerror.New("fmt").WithDebugBlob("blob", []byte(strings.Repeat("b", 3000)), 0))
+}
+
+func TestEstimateJSONSize_Deterministic(t *testing.T) {
+	err := xerror.New("fmt").WithFields(map[string]interface{}{"k1": "v1", "k2": 2, "k3": []string{"a"}})
+	assert.Equal(t, err.EstimateJSONSize(), err.EstimateJSONSize())
+	assert.True(t, xerror.New("fmt", strings.Repeat("a", 1000)).EstimateJSONSize() > xerror.New("fmt").EstimateJSONSize()+1000)
+}
@@ -0,0 +1,53 @@
+package xerror
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DefaultExitCode is the exit code of errors that don't set one explicitly.
+const DefaultExitCode = 1
+
+// WithExitCode returns a copy of the `Error` with the given process exit code, used by `FatalExit`.
+func (e *xerr) WithExitCode(code int) Error {
+	x := e.Clone().(*xerr)
+	x.exit = code
+	return x
+}
+
+// ExitCode returns the process exit code associated with the error, or `DefaultExitCode` if not set.
+func (e *xerr) ExitCode() int {
+	if e.exit == 0 {
+		return DefaultExitCode
+	}
+	return e.exit
+}
+
+// SetExitFunc sets the function called by `FatalExit` to terminate the process. It is intended primarily for tests, to
+// record the exit code instead of exiting. Passing nil restores the default, `os.Exit`.
+func SetExitFunc(fn func(int)) {
+	setConfig(func(c *config) {
+		c.exitFunc = fn
+	})
+}
+
+// FatalExit prints the message of the given error to standard error and terminates the process with its exit code
+// (see `ExitCode`), e.g. at the end of the `main` function of a CLI tool. Go errors, or errors without an `Error` in
+// their chain, exit with `DefaultExitCode`. It does nothing if `err` is nil.
+func FatalExit(err error) {
+	if err == nil {
+		return
+	}
+	code := DefaultExitCode
+	var x *xerr
+	if errors.As(err, &x) {
+		code = x.ExitCode()
+	}
+	_, _ = fmt.Fprintln(os.Stderr, err.Error())
+	exit := getConfig().exitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(code)
+}
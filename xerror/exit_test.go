@@ -0,0 +1,56 @@
+package xerror_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, xerror.DefaultExitCode, err.ExitCode())
+	assert.Equal(t, 3, err.WithExitCode(3).ExitCode())
+	assert.Equal(t, 3, xerror.Wrap(err.WithExitCode(3), "fmt2").ExitCode())
+	assert.Equal(t, xerror.DefaultExitCode, err.ExitCode())
+}
+
+func captureFatalExit(t *testing.T, err error) (string, int) {
+	code := -1
+	xerror.SetExitFunc(func(c int) { code = c })
+	defer xerror.SetExitFunc(nil)
+
+	r, w, e := os.Pipe()
+	assert.Nil(t, e)
+	stderr := os.Stderr
+	os.Stderr = w
+	xerror.FatalExit(err)
+	os.Stderr = stderr
+	assert.Nil(t, w.Close())
+	out, e := io.ReadAll(r)
+	assert.Nil(t, e)
+	return string(out), code
+}
+
+func TestFatalExit(t *testing.T) {
+	out, code := captureFatalExit(t, xerror.Wrap(errors.New("ew"), "fmt").WithExitCode(2))
+	assert.Equal(t, "fmt: ew\n", out)
+	assert.Equal(t, 2, code)
+
+	out, code = captureFatalExit(t, fmt.Errorf("wrapped: %w", xerror.New("fmt").WithExitCode(4)))
+	assert.Equal(t, "wrapped: fmt\n", out)
+	assert.Equal(t, 4, code)
+
+	out, code = captureFatalExit(t, errors.New("ew"))
+	assert.Equal(t, "ew\n", out)
+	assert.Equal(t, xerror.DefaultExitCode, code)
+}
+
+func TestFatalExit_Nil(t *testing.T) {
+	out, code := captureFatalExit(t, nil)
+	assert.Equal(t, "", out)
+	assert.Equal(t, -1, code)
+}
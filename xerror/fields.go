@@ -0,0 +1,77 @@
+package xerror
+
+// Names of the fields set by `WrapSource`.
+const (
+	FieldSource    = "source"
+	FieldOperation = "operation"
+)
+
+// NewWithFields is like `New`, but also sets the given fields on the returned error.
+func NewWithFields(format string, fields map[string]interface{}) Error {
+	xerr := New(format).(*xerr)
+	xerr.fields = mergeFields(nil, fields)
+	return xerr
+}
+
+// WrapWithFields is like `Wrap`, but also sets the given fields on the returned error. Fields already set on `err`
+// are preserved unless overridden.
+func WrapWithFields(err error, format string, fields map[string]interface{}) Error {
+	xerr := Wrap(err, format).(*xerr)
+	xerr.fields = mergeFields(xerr.fields, fields)
+	return xerr
+}
+
+// WrapSource is like `Wrap`, but also records where `err` came from in the `FieldSource` and `FieldOperation` fields,
+// e.g. the library and the call that failed. It allows to attribute third-party failures consistently.
+func WrapSource(err error, source, operation, format string, v ...interface{}) Error {
+	xerr := Wrap(err, format, v...).(*xerr)
+	xerr.fields = mergeFields(xerr.fields, map[string]interface{}{
+		FieldSource:    source,
+		FieldOperation: operation,
+	})
+	return xerr
+}
+
+// WithField returns a copy of the `Error` with the given field set.
+func (e *xerr) WithField(key string, value interface{}) Error {
+	return e.WithFields(map[string]interface{}{key: value})
+}
+
+// WithFields returns a copy of the `Error` with the given fields set, overriding existing fields with the same keys.
+// The given fields are internal, even if they were previously set by `WithPublicField`.
+func (e *xerr) WithFields(fields map[string]interface{}) Error {
+	x := e.Clone().(*xerr)
+	x.fields = mergeFields(x.fields, fields)
+	for k := range fields {
+		if x.pub[k] {
+			x.pub = clonePublic(x.pub)
+			delete(x.pub, k)
+		}
+	}
+	return x
+}
+
+// Fields returns a copy of the fields associated with the error.
+func (e *xerr) Fields() map[string]interface{} {
+	return mergeFields(nil, e.fields)
+}
+
+// mergeFields returns a new map containing the fields in `dst` overridden by the ones in `src`
+func mergeFields(dst, src map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(dst)+len(src))
+	for k, v := range dst {
+		fields[k] = v
+	}
+	for k, v := range src {
+		fields[k] = v
+	}
+	return fields
+}
+
+// cloneFields returns a copy of the fields, or nil if there are none
+func (e *xerr) cloneFields() map[string]interface{} {
+	if e.fields == nil {
+		return nil
+	}
+	return mergeFields(nil, e.fields)
+}
@@ -0,0 +1,83 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewWithFields(t *testing.T) {
+	fields := map[string]interface{}{"userID": 42}
+	err := xerror.NewWithFields("user not found", fields)
+	assert.Equal(t, "user not found", err.Error())
+	assert.Equal(t, map[string]interface{}{"userID": 42}, err.Fields())
+	assert.True(t, xerror.Is(err, "user not found"))
+
+	fields["userID"] = 43
+	assert.Equal(t, map[string]interface{}{"userID": 42}, err.Fields())
+}
+
+func TestNew_NoFields(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{}, xerror.New("fmt").Fields())
+}
+
+func TestWrapWithFields_NativeErr(t *testing.T) {
+	err := xerror.WrapWithFields(errors.New("ew"), "fmt", map[string]interface{}{"k": "v"})
+	assert.Equal(t, "fmt: ew", err.Error())
+	assert.Equal(t, map[string]interface{}{"k": "v"}, err.Fields())
+}
+
+func TestWrapWithFields_Error(t *testing.T) {
+	inner := xerror.NewWithFields("fmt", map[string]interface{}{"k1": "v1", "k2": "v2"})
+	err := xerror.WrapWithFields(inner, "fmt2", map[string]interface{}{"k2": "v3"})
+	assert.Equal(t, "fmt2: fmt", err.Error())
+	assert.Equal(t, map[string]interface{}{"k1": "v1", "k2": "v3"}, err.Fields())
+	assert.Equal(t, map[string]interface{}{"k1": "v1", "k2": "v2"}, inner.Fields())
+}
+
+func TestWrapSource(t *testing.T) {
+	inner := errors.New("connection refused")
+	err := xerror.WrapSource(inner, "redis", "GET", "cache lookup failed for %v", "k1", "d1")
+	assert.Equal(t, "cache lookup failed for k1: connection refused", err.Error())
+	assert.True(t, xerror.Is(err, "cache lookup failed for %v"))
+	assert.True(t, errors.Is(err, inner))
+	assert.Equal(t, []interface{}{"k1", "d1"}, err.Debug())
+	assert.Equal(t, map[string]interface{}{xerror.FieldSource: "redis", xerror.FieldOperation: "GET"}, err.Fields())
+
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, map[string]interface{}{"source": "redis", "operation": "GET"}, m["fields"])
+}
+
+func TestFields_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.NewWithFields("fmt", map[string]interface{}{"k": "v"}))
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, map[string]interface{}{"k": "v"}, m["fields"])
+}
+
+func TestWithField(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithField("k1", "v1").WithField("k2", "v2").WithField("k1", "v3")
+	assert.Equal(t, map[string]interface{}{}, err.Fields())
+	assert.Equal(t, map[string]interface{}{"k1": "v3", "k2": "v2"}, cp.Fields())
+}
+
+func TestWithFields(t *testing.T) {
+	err := xerror.New("fmt").WithField("k1", "v1")
+	cp := err.WithFields(map[string]interface{}{"k1": "v2", "k3": "v3"})
+	assert.Equal(t, map[string]interface{}{"k1": "v1"}, err.Fields())
+	assert.Equal(t, map[string]interface{}{"k1": "v2", "k3": "v3"}, cp.Fields())
+}
+
+func TestFields_SeparateFromDebug(t *testing.T) {
+	err := xerror.New("fmt %v", "p1", "d1").WithField("user_id", 42)
+	assert.Equal(t, []interface{}{"p1", "d1"}, err.Debug())
+	assert.Equal(t, map[string]interface{}{"user_id": 42}, err.Fields())
+	assert.Equal(t, map[string]interface{}{"user_id": 42}, xerror.Wrap(err, "fmt2", "d2").Fields())
+}
@@ -0,0 +1,68 @@
+package xerror
+
+// IsGlob is like `Is`, but matches the outermost message format against the given glob pattern, in which "*" matches
+// any sequence of characters (including none) and "?" matches any single character. All other characters, including
+// "/", match themselves. Like formats, the pattern is normalized by the function set with `SetFormatNormalizer`.
+func (e *xerr) IsGlob(pattern string) bool {
+	return matchGlob(normalizeFormat(pattern), e.fmts[0])
+}
+
+// ContainsGlob is like `Contains`, but matches the message formats against the given glob pattern (see `IsGlob`).
+func (e *xerr) ContainsGlob(pattern string) bool {
+	pattern = normalizeFormat(pattern)
+	for _, f := range e.fmts {
+		if matchGlob(pattern, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGlob is like `Is`, but matches the outermost message format (if `err` is `Error`) or error string (if `err` is a Go
+// `error`) against the given glob pattern (see `Error.IsGlob`).
+func IsGlob(err error, pattern string) bool {
+	if err == nil {
+		return false
+	}
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.IsGlob(pattern)
+	}
+	return matchGlob(pattern, err.Error())
+}
+
+// ContainsGlob is like IsGlob, but in case `err` is of type `Error` matches all attached message formats.
+func ContainsGlob(err error, pattern string) bool {
+	if err == nil {
+		return false
+	}
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.ContainsGlob(pattern)
+	}
+	return matchGlob(pattern, err.Error())
+}
+
+// matchGlob returns true if the given string matches the given glob pattern, false otherwise
+func matchGlob(pattern, s string) bool {
+	p, r := []rune(pattern), []rune(s)
+	pi, ri := 0, 0
+	star, mark := -1, 0
+	for ri < len(r) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == r[ri]):
+			pi++
+			ri++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ri
+			pi++
+		case star >= 0:
+			mark++
+			pi, ri = star+1, mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,65 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsGlob_Method(t *testing.T) {
+	err := xerror.Wrap(xerror.New("db: row %v not found", 1), "http: get /users/%v", 1)
+	assert.True(t, err.IsGlob("http: get /users/%v"))
+	assert.True(t, err.IsGlob("http: *"))
+	assert.True(t, err.IsGlob("*/users/*"))
+	assert.True(t, err.IsGlob("http?*"))
+	assert.False(t, err.IsGlob("db: *"))
+	assert.False(t, err.IsGlob("http?"))
+	assert.False(t, err.IsGlob("http: get /users/"))
+}
+
+func TestContainsGlob_Method(t *testing.T) {
+	err := xerror.Wrap(xerror.New("db: row %v not found", 1), "http: get /users/%v", 1)
+	assert.True(t, err.ContainsGlob("db: *"))
+	assert.True(t, err.ContainsGlob("db: row %? not found"))
+	assert.True(t, err.ContainsGlob("*not found"))
+	assert.False(t, err.ContainsGlob("cache: *"))
+}
+
+func TestGlob_Literal(t *testing.T) {
+	err := xerror.New("a[b]*c")
+	assert.True(t, err.IsGlob("a[b]*c"))
+	assert.False(t, err.IsGlob("a[b]\\*c"))
+	assert.True(t, err.IsGlob("a[b]**"))
+	assert.True(t, err.IsGlob("*"))
+	assert.False(t, err.IsGlob(""))
+	assert.True(t, xerror.New("").IsGlob(""))
+	assert.True(t, xerror.New("").IsGlob("*"))
+	assert.False(t, xerror.New("").IsGlob("?"))
+}
+
+func TestGlob_FormatNormalizer(t *testing.T) {
+	xerror.SetFormatNormalizer(xerror.NormalizeWhitespace)
+	defer xerror.SetFormatNormalizer(nil)
+
+	err := xerror.Wrap(xerror.New("db: row %v\n\tnot found", 1), "http:  get /users/%v", 1)
+	assert.True(t, err.IsGlob("http:  get *"))
+	assert.True(t, err.IsGlob("http: get *"))
+	assert.True(t, err.ContainsGlob("db: row * \n not found"))
+	assert.True(t, xerror.IsGlob(err, " http:\tget *"))
+	assert.True(t, xerror.ContainsGlob(err, "*row %v  not found"))
+}
+
+func TestIsGlob_TopLevel(t *testing.T) {
+	assert.False(t, xerror.IsGlob(nil, "*"))
+	assert.True(t, xerror.IsGlob(errors.New("native error"), "native*"))
+	assert.False(t, xerror.IsGlob(errors.New("native error"), "error"))
+	assert.True(t, xerror.IsGlob(xerror.Wrap(xerror.New("e1"), "e2"), "e?"))
+	assert.False(t, xerror.IsGlob(xerror.Wrap(xerror.New("e1"), "e2"), "e1"))
+}
+
+func TestContainsGlob_TopLevel(t *testing.T) {
+	assert.False(t, xerror.ContainsGlob(nil, "*"))
+	assert.True(t, xerror.ContainsGlob(errors.New("native error"), "*error"))
+	assert.True(t, xerror.ContainsGlob(xerror.Wrap(xerror.New("e1"), "e2"), "?1"))
+}
@@ -0,0 +1,49 @@
+package xerror
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoldenString returns a deterministic multi-line representation of the error, suitable for golden files in snapshot
+// tests. It renders the message, the code, the fields sorted by key, and the stack with program counters removed.
+// Frame paths are made relative to `baseDir`; frames outside of it (e.g. in the Go runtime) are rendered as just the
+// function name, since their paths vary across machines.
+func (e *xerr) GoldenString(baseDir string) string {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "message: %v\n", e.Error())
+	if code := e.Code(); code != "" {
+		fmt.Fprintf(buf, "code: %v\n", code)
+	}
+	if len(e.fields) > 0 {
+		keys := make([]string, 0, len(e.fields))
+		for k := range e.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteString("fields:\n")
+		for _, k := range keys {
+			fmt.Fprintf(buf, "  %v: %v\n", k, e.fields[k])
+		}
+	}
+	buf.WriteString("stack:\n")
+	for _, f := range e.frames() {
+		fmt.Fprintf(buf, "  %v\n", f.goldenString(baseDir))
+	}
+	return buf.String()
+}
+
+// goldenString formats the frame without its program counter and with its path relative to `baseDir`
+func (f Frame) goldenString(baseDir string) string {
+	if f.isBoundary() {
+		return f.Function
+	}
+	rel, err := filepath.Rel(baseDir, f.File)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return f.Function
+	}
+	return fmt.Sprintf("%v:%v %v", filepath.ToSlash(rel), f.Line, f.Function)
+}
@@ -0,0 +1,29 @@
+package xerror_test
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGoldenString(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+
+	e := xerror.NewWithFields("fmt", map[string]interface{}{"k2": "v2", "k1": 1}).WithCode("CODE")
+	golden := e.GoldenString(wd)
+	assert.True(t, strings.HasPrefix(golden, "message: fmt\ncode: CODE\nfields:\n  k1: 1\n  k2: v2\nstack:\n"), golden)
+	assert.Regexp(t, regexp.MustCompile(`(?m)^  golden_test\.go:\d+ github\.com/ibrt/go-xerror/xerror_test\.TestGoldenString$`), golden)
+	assert.NotRegexp(t, regexp.MustCompile(`0x[0-9a-f]+`), golden)
+	assert.NotContains(t, golden, wd)
+}
+
+func TestGoldenString_MessageOnly(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	golden := xerror.New("fmt").GoldenString(wd)
+	assert.True(t, strings.HasPrefix(golden, "message: fmt\nstack:\n"), golden)
+}
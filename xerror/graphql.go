@@ -0,0 +1,29 @@
+package xerror
+
+// Keys of the GraphQL error extensions returned by `GraphQLExtensions`.
+const (
+	GraphQLKeyCode   = "code"
+	GraphQLKeyID     = "id"
+	GraphQLKeyFields = "fields"
+	GraphQLKeyStack  = "stack"
+)
+
+// GraphQLExtensions returns the error as a map suitable for the "extensions" entry of a GraphQL error (e.g.
+// `gqlerror.Error.Extensions`): it contains the code (if set), the instance ID, the public fields (if any, see
+// `WithPublicField`), and the stack (unless in production mode, see `SetProductionMode`). The message is not included, as it belongs to
+// the "message" entry of the GraphQL error itself.
+func (e *xerr) GraphQLExtensions() map[string]interface{} {
+	ext := map[string]interface{}{
+		GraphQLKeyID: e.id,
+	}
+	if code := e.Code(); code != "" {
+		ext[GraphQLKeyCode] = code
+	}
+	if len(e.pub) > 0 {
+		ext[GraphQLKeyFields] = e.PublicFields()
+	}
+	if !ProductionMode() {
+		ext[GraphQLKeyStack] = e.Stack()
+	}
+	return ext
+}
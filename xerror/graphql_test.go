@@ -0,0 +1,43 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGraphQLExtensions(t *testing.T) {
+	err := xerror.New("fmt").WithCode("NOT_FOUND").WithField("k", "v").WithPublicField("pk", "pv")
+	assert.Equal(t, map[string]interface{}{
+		"id":     err.InstanceID(),
+		"code":   "NOT_FOUND",
+		"fields": map[string]interface{}{"pk": "pv"},
+	}, err.GraphQLExtensions())
+}
+
+func TestGraphQLExtensions_InternalFields(t *testing.T) {
+	err := xerror.New("fmt").WithField("k", "v")
+	assert.Equal(t, map[string]interface{}{"id": err.InstanceID()}, err.GraphQLExtensions())
+}
+
+func TestGraphQLExtensions_Minimal(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, map[string]interface{}{"id": err.InstanceID()}, err.GraphQLExtensions())
+}
+
+func TestGraphQLExtensions_Stack(t *testing.T) {
+	xerror.SetProductionMode(false)
+	defer xerror.SetProductionMode(true)
+
+	err := xerror.New("fmt")
+	ext := err.GraphQLExtensions()
+	assert.Equal(t, err.Stack(), ext["stack"])
+
+	_, e := json.Marshal(ext)
+	assert.Nil(t, e)
+}
+
+func TestGraphQLExtensions_ProductionMode(t *testing.T) {
+	assert.NotContains(t, xerror.New("fmt").GraphQLExtensions(), "stack")
+}
@@ -0,0 +1,14 @@
+package xerror
+
+// WithHelpURL returns a copy of the `Error` pointing to the given documentation URL, e.g. a runbook describing how to
+// resolve it. Wrapping preserves the URL unless the outer error sets its own.
+func (e *xerr) WithHelpURL(url string) Error {
+	x := e.Clone().(*xerr)
+	x.helpURL = url
+	return x
+}
+
+// HelpURL returns the documentation URL associated with the error, or an empty string if not set.
+func (e *xerr) HelpURL() string {
+	return e.helpURL
+}
@@ -0,0 +1,33 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithHelpURL(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithHelpURL("https://example.com/runbook")
+	assert.Equal(t, "", err.HelpURL())
+	assert.Equal(t, "https://example.com/runbook", cp.HelpURL())
+}
+
+func TestWithHelpURL_Wrap(t *testing.T) {
+	inner := xerror.New("fmt").WithHelpURL("https://example.com/inner")
+	assert.Equal(t, "https://example.com/inner", xerror.Wrap(inner, "fmt2").HelpURL())
+	assert.Equal(t, "https://example.com/outer", xerror.Wrap(inner, "fmt2").WithHelpURL("https://example.com/outer").HelpURL())
+}
+
+func TestWithHelpURL_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.New("fmt").WithHelpURL("https://example.com/runbook"))
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, "https://example.com/runbook", m["helpUrl"])
+
+	buf, err = json.Marshal(xerror.New("fmt"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(buf), "helpUrl")
+}
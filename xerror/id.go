@@ -0,0 +1,81 @@
+package xerror
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	idMu   sync.Mutex
+	idRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// InstanceID returns the short random ID generated when the error was created. Unlike message formats, which identify
+// a kind of error, it identifies a single occurrence, e.g. to be quoted by users in support requests. Wrapping
+// preserves the ID of the wrapped error.
+func (e *xerr) InstanceID() string {
+	return e.id
+}
+
+// WithInstanceID returns a copy of the `Error` with the given instance ID, replacing the generated one, e.g. to reuse a
+// request or trace ID that callers already quote. Like the generated ID, it is preserved by wrapping.
+func (e *xerr) WithInstanceID(id string) Error {
+	x := e.Clone().(*xerr)
+	x.id = id
+	return x
+}
+
+// ID is an alias of `InstanceID`.
+func (e *xerr) ID() string {
+	return e.InstanceID()
+}
+
+// WithID is an alias of `WithInstanceID`.
+func (e *xerr) WithID(id string) Error {
+	return e.WithInstanceID(id)
+}
+
+// SetIDGenerator sets the function generating the instance IDs of new errors. It is intended primarily for tests, to
+// inject a deterministic generator (e.g. a counter) and get stable snapshots; the function may be called concurrently
+// if errors are created from multiple goroutines. Passing nil restores the default generator, which returns 8 random
+// hex characters and is safe for concurrent use.
+func SetIDGenerator(fn func() string) {
+	setConfig(func(c *config) {
+		c.idGenerator = fn
+	})
+}
+
+// newInstanceID returns a new instance ID using the configured generator
+func newInstanceID() string {
+	if fn := getConfig().idGenerator; fn != nil {
+		return fn()
+	}
+	return randomID()
+}
+
+// randomID returns 8 random hex characters
+func randomID() string {
+	idMu.Lock()
+	defer idMu.Unlock()
+	return fmt.Sprintf("%08x", idRand.Uint32())
+}
+
+// NewOccurrence returns a copy of the `Error` representing a new occurrence of the same logical error, e.g. when a
+// retried operation fails again: it has a new instance ID, its layer timestamps are reset to now, and its stack is
+// captured at the `NewOccurrence` call site.
+func (e *xerr) NewOccurrence() Error {
+	x := e.Clone().(*xerr)
+	x.id = newInstanceID()
+	x.stack = newStack()
+	t := layerTime()
+	for i := range x.times {
+		x.times[i] = t
+	}
+	x.created = now()
+	if !x.wrapped.IsZero() {
+		x.wrapped = x.created
+	}
+	return x
+}
@@ -0,0 +1,107 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"sync"
+	"testing"
+)
+
+func TestInstanceID(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}$`), err.InstanceID())
+	assert.NotEqual(t, err.InstanceID(), xerror.New("fmt").InstanceID())
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}$`), xerror.Wrap(errors.New("ew"), "fmt").InstanceID())
+}
+
+func TestInstanceID_Wrap(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, err.InstanceID(), xerror.Wrap(err, "fmt2").InstanceID())
+	assert.Equal(t, err.InstanceID(), err.Clone().InstanceID())
+}
+
+func TestInstanceID_Concurrent(t *testing.T) {
+	wg := sync.WaitGroup{}
+	ids := make([]string, 100)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i] = xerror.New("fmt").InstanceID()
+		}(i)
+	}
+	wg.Wait()
+	for _, id := range ids {
+		assert.Len(t, id, 8)
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	n := 0
+	xerror.SetIDGenerator(func() string {
+		n++
+		return fmt.Sprintf("id-%v", n)
+	})
+	defer xerror.SetIDGenerator(nil)
+
+	assert.Equal(t, "id-1", xerror.New("fmt").InstanceID())
+	assert.Equal(t, "id-2", xerror.Wrap(errors.New("ew"), "fmt").InstanceID())
+	assert.Equal(t, "id-4", xerror.New("fmt").NewOccurrence().InstanceID())
+
+	xerror.SetIDGenerator(nil)
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}$`), xerror.New("fmt").InstanceID())
+}
+
+func TestInstanceID_JSON(t *testing.T) {
+	err := xerror.New("fmt")
+	buf, err2 := json.Marshal(err)
+	assert.Nil(t, err2)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, err.InstanceID(), m["id"])
+}
+
+func newOccurrence(err xerror.Error) xerror.Error {
+	return err.NewOccurrence()
+}
+
+func TestNewOccurrence(t *testing.T) {
+	xerror.SetLayerTimestamps(true)
+	defer xerror.SetLayerTimestamps(false)
+
+	err := xerror.Wrap(xerror.New("fmt %v", "p1").WithCode("CODE"), "fmt2")
+	cp := newOccurrence(err)
+	assert.Equal(t, err.Error(), cp.Error())
+	assert.Equal(t, err.Debug(), cp.Debug())
+	assert.Equal(t, "CODE", cp.Code())
+	assert.True(t, xerror.Is(cp, "fmt2"))
+	assert.NotEqual(t, err.InstanceID(), cp.InstanceID())
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}$`), cp.InstanceID())
+	assert.True(t, cp.LayerTimestamps()[1].After(err.LayerTimestamps()[1]))
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.newOccurrence", cp.StackFrames()[0].Function)
+}
+
+func TestWithInstanceID(t *testing.T) {
+	orig := xerror.New("fmt")
+	err := orig.WithInstanceID("req-123")
+	assert.Equal(t, "req-123", err.InstanceID())
+	assert.NotEqual(t, "req-123", orig.InstanceID())
+	assert.Equal(t, "req-123", xerror.Wrap(err, "fmt2").InstanceID())
+
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	assert.Contains(t, string(buf), `"id":"req-123"`)
+}
+
+func TestWithID(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, err.InstanceID(), err.ID())
+	err = err.WithID("req-123")
+	assert.Equal(t, "req-123", err.ID())
+	assert.Equal(t, "req-123", err.InstanceID())
+	assert.Equal(t, "req-123", xerror.Wrap(err, "fmt2").ID())
+}
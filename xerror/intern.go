@@ -0,0 +1,46 @@
+package xerror
+
+import (
+	"sync"
+)
+
+// maxInterned is the maximum number of distinct formats kept by the intern cache
+const maxInterned = 4096
+
+var (
+	internMu sync.RWMutex
+	interned = map[string]string{}
+)
+
+// SetFormatInterning enables or disables interning of message formats: when enabled, identical formats passed to
+// `New` and `Wrap` share a single backing string, so that formats built at runtime (e.g. the messages of wrapped Go
+// errors) are not retained multiple times, and comparisons in `Is` and `Contains` short-circuit on identical string
+// pointers. It is meant for services with a small fixed set of formats: at most 4096 distinct formats are interned,
+// further ones are used as they are. It is disabled by default.
+func SetFormatInterning(enabled bool) {
+	setConfig(func(c *config) {
+		c.formatInterning = enabled
+	})
+}
+
+// internFormat returns the interned copy of the given format if interning is enabled, the format itself otherwise
+func internFormat(format string) string {
+	if !getConfig().formatInterning {
+		return format
+	}
+	internMu.RLock()
+	s, ok := interned[format]
+	internMu.RUnlock()
+	if ok {
+		return s
+	}
+	internMu.Lock()
+	defer internMu.Unlock()
+	if s, ok := interned[format]; ok {
+		return s
+	}
+	if len(interned) < maxInterned {
+		interned[format] = format
+	}
+	return format
+}
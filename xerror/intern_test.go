@@ -0,0 +1,112 @@
+package xerror_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+const longFormat = " a long error message format that takes a while to compare byte by byte, as it happens when the" +
+	" same message is built at runtime in many places instead of coming from a single constant; interning lets Contains" +
+	" compare the backing pointers of the two strings instead"
+
+var benchFormats = []string{
+	"user %v not found",
+	"invalid value for field %v",
+	"unable to execute query",
+	"permission denied",
+}
+
+func TestSetFormatInterning(t *testing.T) {
+	xerror.SetFormatInterning(true)
+	defer xerror.SetFormatInterning(false)
+
+	f1 := strings.Repeat("ab", 2)
+	f2 := strings.Repeat("ab", 2)
+	err := xerror.Wrap(xerror.New(f1), f2)
+	assert.Equal(t, "abab: abab", err.Error())
+	assert.True(t, xerror.Is(err, "abab"))
+	assert.True(t, err.Contains(f1))
+	assert.True(t, xerror.Wrap(errors.New(f2), "fmt").Contains("abab"))
+}
+
+func TestSetFormatInterning_SharedStorage(t *testing.T) {
+	f1 := strings.Repeat("cd", 2)
+	f2 := strings.Repeat("cd", 2)
+	assert.NotSame(t, unsafe.StringData(f1), unsafe.StringData(f2))
+
+	formats := xerror.Wrap(xerror.New(f1), f2).Messages()
+	assert.NotSame(t, unsafe.StringData(formats[0]), unsafe.StringData(formats[1]))
+
+	xerror.SetFormatInterning(true)
+	defer xerror.SetFormatInterning(false)
+
+	formats = xerror.Wrap(xerror.New(f1), f2).Messages()
+	assert.Same(t, unsafe.StringData(f1), unsafe.StringData(formats[0]))
+	assert.Same(t, unsafe.StringData(f1), unsafe.StringData(formats[1]))
+	formats = xerror.Wrap(errors.New(strings.Repeat("cd", 2)), "fmt").Messages()
+	assert.Same(t, unsafe.StringData(f1), unsafe.StringData(formats[1]))
+}
+
+func BenchmarkWrap_DynamicFormats(b *testing.B) {
+	benchmarkDynamicFormats(b, false)
+}
+
+func BenchmarkWrap_DynamicFormatsInterning(b *testing.B) {
+	benchmarkDynamicFormats(b, true)
+}
+
+// benchmarkDynamicFormats wraps errors with formats built at runtime from a small set of distinct values, and reports
+// the heap retained per error in addition to allocations
+func benchmarkDynamicFormats(b *testing.B, interning bool) {
+	xerror.SetFormatInterning(interning)
+	defer xerror.SetFormatInterning(false)
+	native := errors.New("ew")
+	retained := make([]xerror.Error, 0, b.N)
+	before := heapInUse()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		format := fmt.Sprintf("%v (%v)", benchFormats[i%len(benchFormats)], strings.Repeat("x", 256))
+		retained = append(retained, xerror.Wrap(native, format))
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(heapInUse()-before)/float64(b.N), "retained-B/op")
+	runtime.KeepAlive(retained)
+}
+
+func BenchmarkContains_NoInterning(b *testing.B) {
+	benchmarkContains(b, false)
+}
+
+func BenchmarkContains_Interning(b *testing.B) {
+	benchmarkContains(b, true)
+}
+
+// benchmarkContains looks up a long format constant in errors created from a copy of it built at runtime
+func benchmarkContains(b *testing.B, interning bool) {
+	xerror.SetFormatInterning(interning)
+	defer xerror.SetFormatInterning(false)
+	xerror.New(longFormat)
+	err := xerror.Wrap(xerror.New(strings.Repeat(" ", 1)+longFormat[1:]), "outer")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !err.Contains(longFormat) {
+			b.Fatal("not found")
+		}
+	}
+}
+
+// heapInUse returns the bytes of heap in use after a garbage collection
+func heapInUse() int64 {
+	runtime.GC()
+	stats := runtime.MemStats{}
+	runtime.ReadMemStats(&stats)
+	return int64(stats.HeapInuse)
+}
@@ -0,0 +1,139 @@
+package xerror
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchemaVersion is the version of the JSON representation produced by `MarshalJSON`, serialized as
+// "schemaVersion". It is bumped whenever the representation changes. JSON without a version is version 1. Versions
+// have only added attributes so far, without changing the meaning of existing ones:
+//   - 2 added "schemaVersion";
+//   - 3 added "retryable";
+//   - 4 added "checkpoints";
+//   - 5 added "createdAt" and "wrappedAt";
+//   - 6 added "separator";
+//   - 7 added "combined".
+const JSONSchemaVersion = 7
+
+// ErrorUnsupportedSchemaVersion is the message format of the error returned by `UnmarshalJSON` for JSON produced by a
+// newer version of the package.
+const ErrorUnsupportedSchemaVersion = "unsupported JSON schema version %v"
+
+var frameStringRegexp = regexp.MustCompile(`^(.+):(\d+)(?: \((?:0x[0-9a-f]+|(.+))\))?$`)
+
+// xerrJSONIn is used to deserialize Error from JSON
+type xerrJSONIn struct {
+	xerrJSON
+	Children []json.RawMessage `json:"children"`
+}
+
+// UnmarshalJSON reconstructs an `Error` from the JSON produced by `MarshalJSON`, see the `UnmarshalJSON` method.
+func UnmarshalJSON(buf []byte) (Error, error) {
+	e := &xerr{}
+	if err := e.UnmarshalJSON(buf); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// UnmarshalJSON implements the `json.Unmarshaler` interface, reconstructing the error from the JSON produced by
+// `MarshalJSON`, e.g. in another process. The message formats are restored, so that `Is` and `Contains` keep working
+// after a round trip. Some information can't be fully recovered:
+//
+//   - debug objects and fields come back as the generic values produced by `encoding/json` (e.g. `float64` for
+//     numbers, `map[string]interface{}` for structs);
+//   - the layer messages (see `MessageChain`) are recovered by splitting the message on the separator (the one set by
+//     `WithSeparator`, which is serialized, or else the one set by `SetMessageSeparator` when unmarshaling), which is
+//     ambiguous if layer messages contain the separator themselves;
+//   - stack frames have no PC, as after `Materialize`;
+//   - the code is restored on the outermost layer, regardless of the layer it was originally set on;
+//   - attributes that are not serialized, such as layer timestamps and the wrapped error, are lost;
+//   - Go error children become errors without a stack.
+//
+// JSON of older schema versions (see `JSONSchemaVersion`) is read as if it were of the current version: since versions
+// only added attributes, the attributes it lacks are simply left unset. JSON of newer versions is rejected with an
+// `ErrorUnsupportedSchemaVersion` error.
+func (e *xerr) UnmarshalJSON(buf []byte) error {
+	j := &xerrJSONIn{}
+	if err := json.Unmarshal(buf, j); err != nil {
+		return err
+	}
+	if version := schemaVersion(j.Version); version > JSONSchemaVersion {
+		return New(ErrorUnsupportedSchemaVersion, version)
+	}
+	fmts := j.Formats
+	if len(fmts) == 0 {
+		fmts = []string{j.Message}
+	}
+	children := make([]error, 0, len(j.Children))
+	for _, c := range j.Children {
+		child := &xerr{}
+		if err := child.UnmarshalJSON(c); err != nil {
+			return err
+		}
+		children = append(children, child)
+	}
+	*e = xerr{
+		msg:         j.Message,
+		fmts:        fmts,
+		sep:         j.Separator,
+		combined:    j.Combined,
+		dbg:         nilToEmpty(j.Debug),
+		stack:       resolvedStack(parseStack(j.Stack)),
+		codes:       make([]string, len(fmts)),
+		times:       make([]time.Time, len(fmts)),
+		fields:      j.Fields,
+		helpURL:     j.HelpURL,
+		severity:    parseSeverity(j.Severity),
+		httpStatus:  j.HTTPStatus,
+		retry:       j.Retryable,
+		checks:      j.Checkpoints,
+		created:     parseTime(j.CreatedAt),
+		wrapped:     parseTime(j.WrappedAt),
+		opKey:       j.OpKey,
+		id:          j.ID,
+		spanContext: j.SpanContext,
+	}
+	e.layers = splitLayers(j.Message, e.separator(), len(fmts))
+	e.codes[0] = j.Code
+	if len(children) > 0 {
+		e.children = children
+	}
+	return nil
+}
+
+// schemaVersion returns the schema version of deserialized JSON, treating unversioned JSON as version 1
+func schemaVersion(version int) int {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
+
+// splitLayers splits the given message into `n` layer messages
+func splitLayers(msg, sep string, n int) []string {
+	layers := strings.SplitN(msg, sep, n)
+	for len(layers) < n {
+		layers = append(layers, "")
+	}
+	return layers
+}
+
+// parseStack parses the given stack strings, as returned by `Stack`, into frames without PC, keeping function names
+// when present
+func parseStack(stack []string) []Frame {
+	frames := make([]Frame, 0, len(stack))
+	for _, s := range stack {
+		if m := frameStringRegexp.FindStringSubmatch(s); m != nil {
+			line, _ := strconv.Atoi(m[2])
+			frames = append(frames, Frame{File: m[1], Line: line, Function: m[3]})
+		} else {
+			frames = append(frames, Frame{Function: s})
+		}
+	}
+	return frames
+}
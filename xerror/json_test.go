@@ -0,0 +1,138 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUnmarshalJSON(t *testing.T) {
+	orig := xerror.Wrap(xerror.New("fmt %v", "p1", 2), "fmt2").
+		WithField("k", "v").
+		WithHelpURL("https://example.com").
+		WithSpanContext(xerror.SpanContext{TraceID: "t", SpanID: "s"}).
+		WithSeverity(xerror.SeverityWarning)
+	buf, err := json.Marshal(orig)
+	assert.Nil(t, err)
+
+	e, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "fmt2: fmt p1", e.Error())
+	assert.True(t, xerror.Is(e, "fmt2"))
+	assert.True(t, e.Contains("fmt %v"))
+	assert.Equal(t, []string{"fmt2", "fmt p1"}, e.MessageChain())
+	assert.Equal(t, []interface{}{"p1", 2.0}, e.Debug())
+	assert.Equal(t, map[string]interface{}{"k": "v"}, e.Fields())
+	assert.Equal(t, "https://example.com", e.HelpURL())
+	assert.Equal(t, xerror.SeverityWarning, e.Severity())
+	assert.Equal(t, orig.InstanceID(), e.InstanceID())
+	sc, ok := e.SpanContext()
+	assert.True(t, ok)
+	assert.Equal(t, xerror.SpanContext{TraceID: "t", SpanID: "s"}, sc)
+	assert.Len(t, e.StackFrames(), len(orig.StackFrames()))
+	for i, f := range e.StackFrames() {
+		assert.Equal(t, orig.StackFrames()[i].File, f.File)
+		assert.Equal(t, orig.StackFrames()[i].Line, f.Line)
+		assert.Equal(t, uintptr(0), f.PC)
+	}
+
+	buf2, err := json.Marshal(e)
+	assert.Nil(t, err)
+	e2, err := xerror.UnmarshalJSON(buf2)
+	assert.Nil(t, err)
+	assert.Equal(t, e.Error(), e2.Error())
+	assert.Equal(t, e.Stack(), e2.Stack())
+	assert.Equal(t, e.StackFrames()[0].Function, e2.StackFrames()[0].Function)
+}
+
+func TestUnmarshalJSON_ParentStack(t *testing.T) {
+	orig := xerror.WrapWithParentStack(errors.New("ew"), xerror.GoStack(), "fmt")
+	buf, err := json.Marshal(orig)
+	assert.Nil(t, err)
+	e, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Contains(t, e.Stack(), "--- goroutine launched from ---")
+	assert.True(t, e.HasStack())
+}
+
+func TestUnmarshalJSON_Children(t *testing.T) {
+	orig := xerror.New("root").WithChildren(xerror.New("a"), errors.New("native"))
+	buf, err := json.Marshal(orig)
+	assert.Nil(t, err)
+	e, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Len(t, e.Children(), 2)
+	assert.True(t, xerror.Is(e.Children()[0], "a"))
+	assert.Equal(t, "native", e.Children()[1].Error())
+}
+
+func TestUnmarshalJSON_NoFormats(t *testing.T) {
+	e, err := xerror.UnmarshalJSON([]byte(`{"message": "fmt2: fmt", "stack": []}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "fmt2: fmt", e.Error())
+	assert.True(t, xerror.Is(e, "fmt2: fmt"))
+	assert.Equal(t, []interface{}{}, e.Debug())
+	assert.False(t, e.HasStack())
+}
+
+func TestUnmarshalJSON_Invalid(t *testing.T) {
+	e, err := xerror.UnmarshalJSON([]byte(`{`))
+	assert.NotNil(t, err)
+	assert.Nil(t, e)
+
+	var into xerror.Error = xerror.New("fmt")
+	assert.NotNil(t, json.Unmarshal([]byte(`[]`), into))
+}
+
+func TestUnmarshalJSON_SchemaVersion(t *testing.T) {
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").GoString()), &m))
+	assert.Equal(t, float64(xerror.JSONSchemaVersion), m["schemaVersion"])
+
+	v1 := `{"id":"abcd1234","message":"fmt2: fmt p1","formats":["fmt2","fmt %v"],"debug":["p1"],` +
+		`"stack":["/path/to/file.go:49 (0x8448b)"]}`
+	e, err := xerror.UnmarshalJSON([]byte(v1))
+	assert.Nil(t, err)
+	assert.Equal(t, "fmt2: fmt p1", e.Error())
+	assert.True(t, e.Contains("fmt %v"))
+	assert.Equal(t, "abcd1234", e.InstanceID())
+	assert.Equal(t, xerror.DefaultSeverity, e.Severity())
+	assert.Equal(t, []string{"/path/to/file.go:49"}, e.Stack())
+
+	buf, err := json.Marshal(e)
+	assert.Nil(t, err)
+	e2, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, e.Error(), e2.Error())
+	assert.Equal(t, e.Stack(), e2.Stack())
+}
+
+func TestUnmarshalJSON_OlderSchemaVersions(t *testing.T) {
+	v2 := `{"schemaVersion":2,"id":"abcd1234","message":"fmt","formats":["fmt"],"code":"CODE","severity":"warning",` +
+		`"stack":["/path/to/file.go:49 (main.main)"]}`
+	e, err := xerror.UnmarshalJSON([]byte(v2))
+	assert.Nil(t, err)
+	assert.Equal(t, "CODE", e.Code())
+	assert.Equal(t, xerror.SeverityWarning, e.Severity())
+	assert.False(t, xerror.IsRetryable(e))
+	assert.Empty(t, e.Checkpoints())
+	assert.True(t, e.CreatedAt().IsZero())
+	assert.Equal(t, []string{"/path/to/file.go:49 (main.main)"}, e.Stack())
+
+	v4 := `{"schemaVersion":4,"message":"fmt2: fmt","formats":["fmt2","fmt"],"retryable":true,` +
+		`"checkpoints":[{"name":"start","at":"1970-01-01T00:16:40Z"}],"stack":[]}`
+	e, err = xerror.UnmarshalJSON([]byte(v4))
+	assert.Nil(t, err)
+	assert.True(t, xerror.IsRetryable(e))
+	assert.Equal(t, "start", e.Checkpoints()[0].Name)
+	assert.True(t, e.WrappedAt().IsZero())
+	assert.Equal(t, []string{"fmt2", "fmt"}, e.MessageChain())
+}
+
+func TestUnmarshalJSON_NewerSchemaVersion(t *testing.T) {
+	_, err := xerror.UnmarshalJSON([]byte(fmt.Sprintf(`{"schemaVersion":%d,"message":"fmt"}`, xerror.JSONSchemaVersion+1)))
+	assert.True(t, xerror.Is(err, xerror.ErrorUnsupportedSchemaVersion))
+}
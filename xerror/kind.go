@@ -0,0 +1,44 @@
+package xerror
+
+// DefaultKindMessage is the message used by `WrapKind` for kinds without a registered message.
+const DefaultKindMessage = "unexpected error"
+
+var kindMessages = map[Kind]string{}
+
+// RegisterKindMessage registers the default message of the given kind (e.g. "not found" for "NotFound"), used by
+// `WrapKind`. It is safe for concurrent use.
+func RegisterKindMessage(kind Kind, msg string) {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	kindMessages[kind] = msg
+}
+
+// lookupKindMessage returns the message registered for the given kind, or `DefaultKindMessage`
+func lookupKindMessage(kind Kind) string {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	if msg, ok := kindMessages[kind]; ok {
+		return msg
+	}
+	return DefaultKindMessage
+}
+
+// WrapKind is like `Wrap`, but uses the message registered for the given kind with `RegisterKindMessage` (or
+// `DefaultKindMessage` if none is) as the message format, and sets the kind of the returned error.
+func WrapKind(err error, kind Kind) Error {
+	xerr := Wrap(err, lookupKindMessage(kind)).(*xerr)
+	xerr.kind = kind
+	return xerr
+}
+
+// Kind returns the kind set by `WrapKind`, or else the kind registered with `RegisterKind` for the error's code, or an
+// empty string if neither is set.
+func (e *xerr) Kind() Kind {
+	if e.kind != "" {
+		return e.kind
+	}
+	if info, ok := lookupCode(e.Code()); ok {
+		return info.Kind
+	}
+	return ""
+}
@@ -0,0 +1,31 @@
+package xerror_test
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestWrapKind(t *testing.T) {
+	xerror.RegisterKindMessage("NotFoundTest", "not found")
+	err := xerror.WrapKind(io.EOF, "NotFoundTest")
+	assert.Equal(t, "not found: EOF", err.Error())
+	assert.True(t, xerror.Is(err, "not found"))
+	assert.Equal(t, xerror.Kind("NotFoundTest"), err.Kind())
+	assert.Equal(t, xerror.Kind("NotFoundTest"), xerror.Wrap(err, "fmt").Kind())
+}
+
+func TestWrapKind_Unregistered(t *testing.T) {
+	err := xerror.WrapKind(xerror.New("fmt"), "UnregisteredKindTest")
+	assert.Equal(t, "unexpected error: fmt", err.Error())
+	assert.True(t, xerror.Is(err, xerror.DefaultKindMessage))
+	assert.Equal(t, xerror.Kind("UnregisteredKindTest"), err.Kind())
+}
+
+func TestKind_FromCode(t *testing.T) {
+	xerror.RegisterKind("KIND_FROM_CODE_TEST", "ConflictTest")
+	assert.Equal(t, xerror.Kind("ConflictTest"), xerror.New("fmt").WithCode("KIND_FROM_CODE_TEST").Kind())
+	assert.Equal(t, xerror.Kind(""), xerror.New("fmt").Kind())
+	assert.Equal(t, xerror.Kind(""), xerror.New("fmt").WithCode("UNREGISTERED_KIND_TEST").Kind())
+}
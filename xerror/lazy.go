@@ -0,0 +1,87 @@
+package xerror
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// lazyLayer is a layer message computed on first render
+type lazyLayer struct {
+	once sync.Once
+	fn   func() string
+	msg  string
+}
+
+// String returns the layer message, invoking the function on the first call only
+func (l *lazyLayer) String() string {
+	l.once.Do(func() {
+		l.msg = l.fn()
+		l.fn = nil
+	})
+	return l.msg
+}
+
+// WrapLazy is like `Wrap`, but the message of the new layer is computed by calling `fn` the first time the error is
+// rendered (e.g. by `Error`), which saves building messages for errors that are never rendered. `fn` is invoked at most
+// once, and its result is cached and shared by all the copies of the error. The format of the new layer, as matched by
+// `Is` and `Contains`, is the empty string. It returns nil if `err` is nil.
+func WrapLazy(err error, fn func() string) Error {
+	if err == nil {
+		return nil
+	}
+	xerr := cloneOrNew(err)
+	if xerr.lazy == nil {
+		xerr.lazy = make([]*lazyLayer, len(xerr.layers))
+	}
+	xerr.cause = err
+	xerr.fmts = append([]string{""}, xerr.fmts...)
+	xerr.layers = append([]string{""}, xerr.layers...)
+	xerr.lazy = append([]*lazyLayer{{fn: fn}}, xerr.lazy...)
+	xerr.times = append([]time.Time{layerTime()}, xerr.times...)
+	xerr.codes = append([]string{""}, xerr.codes...)
+	xerr.wrapped = now()
+	return xerr
+}
+
+// isLazy returns true if the layer at the given index is computed lazily
+func (e *xerr) isLazy(i int) bool {
+	return e.lazy != nil && e.lazy[i] != nil
+}
+
+// renderedLayers returns the layer messages, computing lazy layers if needed
+func (e *xerr) renderedLayers() []string {
+	layers := append(make([]string, 0, len(e.layers)), e.layers...)
+	if e.lazy != nil {
+		for i, l := range e.lazy {
+			if l != nil {
+				layers[i] = l.String()
+			}
+		}
+	}
+	return layers
+}
+
+// message returns the unredacted message, computing lazy layers and joining them with the separator if needed
+func (e *xerr) message() string {
+	sep := e.separator()
+	if e.lazy == nil && sep == DefaultSeparator {
+		return e.msg
+	}
+	return strings.Join(e.renderedLayers(), sep)
+}
+
+// prependEagerLayers keeps the lazy layers aligned after `n` eager layers were prepended
+func (e *xerr) prependEagerLayers(n int) {
+	if e.lazy != nil {
+		e.lazy = append(make([]*lazyLayer, n), e.lazy...)
+	}
+}
+
+// cloneLazy returns a copy of the lazy layers, or nil if there are none
+func (e *xerr) cloneLazy() []*lazyLayer {
+	if e.lazy == nil {
+		return nil
+	}
+	return append(make([]*lazyLayer, 0, len(e.lazy)), e.lazy...)
+}
@@ -0,0 +1,71 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestWrapLazy(t *testing.T) {
+	calls := 0
+	err := xerror.WrapLazy(io.EOF, func() string {
+		calls++
+		return "reading 42 records"
+	})
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "reading 42 records: EOF", err.Error())
+	assert.Equal(t, "reading 42 records: EOF", err.Error())
+	assert.Equal(t, 1, calls)
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.True(t, xerror.Is(err, ""))
+	assert.True(t, err.Contains("EOF"))
+	assert.Equal(t, []string{"reading 42 records", "EOF"}, err.MessageChain())
+}
+
+func TestWrapLazy_Nil(t *testing.T) {
+	assert.Nil(t, xerror.WrapLazy(nil, func() string { return "fmt" }))
+}
+
+func TestWrapLazy_Wrap(t *testing.T) {
+	calls := 0
+	inner := xerror.WrapLazy(xerror.New("fmt1"), func() string {
+		calls++
+		return "lazy"
+	})
+	outer := xerror.Wrap(inner, "fmt2").WithMessages("fmt3")
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, "fmt3: fmt2: lazy: fmt1", outer.Error())
+	assert.Equal(t, "lazy: fmt1", inner.Error())
+	assert.Equal(t, "lazy: fmt1", inner.Clone().Error())
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "lazy: lazy: fmt1", xerror.WrapLazy(inner, func() string { return "lazy" }).Error())
+}
+
+func TestWrapLazy_DuplicateWrap(t *testing.T) {
+	xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapSkip)
+	defer xerror.SetDuplicateWrapPolicy(xerror.DuplicateWrapAllow)
+
+	inner := xerror.WrapLazy(xerror.New("fmt1"), func() string { return "lazy" })
+	assert.Equal(t, ": lazy: fmt1", xerror.Wrap(inner, "").Error())
+}
+
+func TestWrapLazy_Concurrent(t *testing.T) {
+	calls := 0
+	err := xerror.WrapLazy(io.EOF, func() string {
+		calls++
+		return "lazy"
+	})
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, "lazy: EOF", err.Error())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+}
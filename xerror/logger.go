@@ -0,0 +1,47 @@
+package xerror
+
+import (
+	"errors"
+)
+
+// SetLogger registers a function invoked by `WrapLog` with the errors it creates. Passing nil unregisters it.
+func SetLogger(fn func(Error)) {
+	setConfig(func(c *config) {
+		c.logger = fn
+	})
+}
+
+// WrapLog is like `Wrap`, but also passes the returned error to the logger registered with `SetLogger`, if any. It is
+// meant for code that prefers logging at the point of wrapping: errors created by `WrapLog` are logged again if
+// callers up the stack also log them, so pick one of the two patterns.
+func WrapLog(err error, format string, v ...interface{}) Error {
+	xerr := Wrap(err, format, v...)
+	if fn := getConfig().logger; fn != nil {
+		fn(xerr)
+	}
+	return xerr
+}
+
+// MarkReported returns a copy of the `Error` flagged as already reported (e.g. logged), so that handlers up the stack
+// can skip reporting it again. The flag survives wrapping. It is advisory: nothing in this package consults it, callers
+// must check `IsReported` themselves.
+func (e *xerr) MarkReported() Error {
+	x := e.Clone().(*xerr)
+	x.reported = true
+	return x
+}
+
+// IsReported returns true if the error was flagged by `MarkReported`, false otherwise.
+func (e *xerr) IsReported() bool {
+	return e.reported
+}
+
+// IsReported is like the `IsReported` method, but accepts any `error`, looking for an `Error` in its chain. It returns
+// false for Go errors.
+func IsReported(err error) bool {
+	var x *xerr
+	if errors.As(err, &x) {
+		return x.reported
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package xerror_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWrapLog(t *testing.T) {
+	logged := []xerror.Error{}
+	xerror.SetLogger(func(err xerror.Error) {
+		logged = append(logged, err)
+	})
+	defer xerror.SetLogger(nil)
+
+	err := xerror.WrapLog(errors.New("ew"), "fmt %v", "p1")
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.Equal(t, []xerror.Error{err}, logged)
+}
+
+func TestWrapLog_NoLogger(t *testing.T) {
+	xerror.SetLogger(nil)
+	err := xerror.WrapLog(errors.New("ew"), "fmt")
+	assert.Equal(t, "fmt: ew", err.Error())
+}
+
+func TestMarkReported(t *testing.T) {
+	err := xerror.New("fmt")
+	reported := err.MarkReported()
+	assert.False(t, err.IsReported())
+	assert.True(t, reported.IsReported())
+	assert.True(t, xerror.Wrap(reported, "fmt2").IsReported())
+}
+
+func TestIsReported_TopLevel(t *testing.T) {
+	reported := xerror.New("fmt").MarkReported()
+	assert.True(t, xerror.IsReported(reported))
+	assert.True(t, xerror.IsReported(fmt.Errorf("native: %w", reported)))
+	assert.False(t, xerror.IsReported(xerror.New("fmt")))
+	assert.False(t, xerror.IsReported(errors.New("ew")))
+	assert.False(t, xerror.IsReported(nil))
+}
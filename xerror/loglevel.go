@@ -0,0 +1,59 @@
+package xerror
+
+import (
+	"errors"
+)
+
+// Log levels returned by `LogLevel`.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+	LogLevelFatal = "fatal"
+)
+
+// MarkExpected returns a copy of the `Error` flagged as expected, i.e. part of the normal operation of the program
+// (e.g. a lookup of a missing record), so that log adapters can downgrade it. The flag survives wrapping.
+func (e *xerr) MarkExpected() Error {
+	x := e.Clone().(*xerr)
+	x.expected = true
+	return x
+}
+
+// IsExpected returns true if the error was flagged by `MarkExpected` or its kind (see `Kind`) was registered with
+// `RegisterExpectedKind`, false otherwise.
+func (e *xerr) IsExpected() bool {
+	return e.expected || isExpectedKind(e.Kind())
+}
+
+// LogLevel returns the level at which the error should be logged, derived from its severity: "debug", "info", "warn",
+// "error", or "fatal". Expected errors (see `IsExpected`) are downgraded to "info", unless their severity is lower.
+func (e *xerr) LogLevel() string {
+	s := e.Severity()
+	if e.IsExpected() && !SeverityInfo.AtLeast(s) {
+		s = SeverityInfo
+	}
+	switch {
+	case s.AtLeast(SeverityFatal):
+		return LogLevelFatal
+	case s.AtLeast(SeverityError):
+		return LogLevelError
+	case s.AtLeast(SeverityWarning):
+		return LogLevelWarn
+	case s.AtLeast(SeverityInfo):
+		return LogLevelInfo
+	default:
+		return LogLevelDebug
+	}
+}
+
+// LogLevel is like the `LogLevel` method, but accepts any `error`, looking for an `Error` in its chain. It returns
+// "error" for Go errors.
+func LogLevel(err error) string {
+	var x *xerr
+	if errors.As(err, &x) {
+		return x.LogLevel()
+	}
+	return LogLevelError
+}
@@ -0,0 +1,53 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLogLevel(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Equal(t, "error", err.LogLevel())
+	assert.Equal(t, "debug", err.WithSeverity(xerror.SeverityDebug).LogLevel())
+	assert.Equal(t, "info", err.WithSeverity(xerror.SeverityInfo).LogLevel())
+	assert.Equal(t, "warn", err.WithSeverity(xerror.SeverityWarning).LogLevel())
+	assert.Equal(t, "error", err.WithSeverity(xerror.SeverityError).LogLevel())
+	assert.Equal(t, "fatal", err.WithSeverity(xerror.SeverityFatal).LogLevel())
+}
+
+func TestMarkExpected(t *testing.T) {
+	err := xerror.New("not found")
+	cp := err.MarkExpected()
+	assert.False(t, err.IsExpected())
+	assert.True(t, cp.IsExpected())
+	assert.True(t, xerror.Wrap(cp, "fmt").IsExpected())
+	assert.Equal(t, "info", cp.LogLevel())
+	assert.Equal(t, "info", cp.WithSeverity(xerror.SeverityFatal).LogLevel())
+	assert.Equal(t, "debug", cp.WithSeverity(xerror.SeverityDebug).LogLevel())
+}
+
+func TestLogLevel_TopLevel(t *testing.T) {
+	assert.Equal(t, "error", xerror.LogLevel(errors.New("ew")))
+	assert.Equal(t, "warn", xerror.LogLevel(xerror.New("fmt").WithSeverity(xerror.SeverityWarning)))
+	assert.Equal(t, "info", xerror.LogLevel(xerror.New("fmt").MarkExpected()))
+}
+
+func TestLogLevel_ExpectedKind(t *testing.T) {
+	xerror.RegisterExpectedKind("ExpectedKind")
+	xerror.RegisterKind("EXPECTED_KIND_CODE", "ExpectedKind")
+
+	err := xerror.WrapKind(errors.New("ew"), "ExpectedKind")
+	assert.True(t, err.IsExpected())
+	assert.Equal(t, "info", err.LogLevel())
+	assert.Equal(t, "debug", err.WithSeverity(xerror.SeverityDebug).LogLevel())
+
+	err = xerror.New("fmt").WithCode("EXPECTED_KIND_CODE")
+	assert.True(t, err.IsExpected())
+	assert.Equal(t, "info", xerror.LogLevel(err))
+
+	err = xerror.WrapKind(errors.New("ew"), "UnexpectedKind")
+	assert.False(t, err.IsExpected())
+	assert.Equal(t, "error", err.LogLevel())
+}
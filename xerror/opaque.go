@@ -0,0 +1,41 @@
+package xerror
+
+// opaque is the minimal error returned by `Opaque`
+type opaque struct {
+	err *xerr
+}
+
+// Opaque returns a minimal Go `error` that stands for the `Error` at a trust boundary: it keeps `errors.Is`
+// matchability but hides the debug objects, stack, fields, and every other method of `Error`. It only forwards:
+//
+//   - `Error`, returning the same (redacted) message;
+//   - `Is`, reporting whether the target is the `Error` it was created from or matches it as the `Is` method of
+//     `Error` does (e.g. a sentinel with the same formats, or one of the children of a `Join`);
+//   - `Unwrap`, returning the wrapped error, itself made opaque if it is an `Error`.
+//
+// As a consequence `errors.As` can't be used to retrieve an `Error` from the returned error or its chain.
+func (e *xerr) Opaque() error {
+	return &opaque{err: e}
+}
+
+// Error implements the `error` interface.
+func (o *opaque) Error() string {
+	return o.err.Error()
+}
+
+// Is returns true if the target is the `Error` the opaque error was created from, or if that `Error` matches the target
+// (see the `Is` method of `Error`), false otherwise.
+func (o *opaque) Is(target error) bool {
+	if x, ok := target.(*xerr); ok && x == o.err {
+		return true
+	}
+	return o.err.Is(target)
+}
+
+// Unwrap returns the error wrapped by the `Error` the opaque error was created from, made opaque if it is an `Error`.
+func (o *opaque) Unwrap() error {
+	if x, ok := o.err.cause.(*xerr); ok {
+		return x.Opaque()
+	}
+	return o.err.cause
+}
@@ -0,0 +1,56 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"regexp"
+	"testing"
+)
+
+func TestOpaque(t *testing.T) {
+	err := xerror.Wrap(io.EOF, "fmt %v", "p1", "d1").WithField("k", "v")
+	op := err.Opaque()
+	assert.Equal(t, "fmt p1: EOF", op.Error())
+	assert.True(t, errors.Is(op, io.EOF))
+	assert.True(t, errors.Is(op, err))
+	assert.False(t, errors.Is(op, errors.New("EOF")))
+
+	_, ok := op.(xerror.Error)
+	assert.False(t, ok)
+	var x xerror.Error
+	assert.False(t, errors.As(op, &x))
+}
+
+func TestOpaque_Chain(t *testing.T) {
+	inner := xerror.Wrap(io.EOF, "inner")
+	outer := xerror.Wrap(inner, "outer")
+	op := outer.Opaque()
+	assert.True(t, errors.Is(op, io.EOF))
+	assert.True(t, errors.Is(op, inner))
+	assert.Equal(t, "inner: EOF", errors.Unwrap(op).Error())
+
+	var x xerror.Error
+	assert.False(t, errors.As(op, &x))
+}
+
+func TestOpaque_Sentinel(t *testing.T) {
+	sentinel := xerror.New("not found")
+	assert.True(t, errors.Is(sentinel.WithCode("X").Opaque(), sentinel))
+	assert.True(t, errors.Is(xerror.Wrap(sentinel.WithCode("X"), "fmt").Opaque(), sentinel))
+	assert.False(t, errors.Is(xerror.New("other").Opaque(), sentinel))
+}
+
+func TestOpaque_Join(t *testing.T) {
+	sentinel := xerror.New("not found")
+	op := xerror.Join(sentinel, errors.New("b")).Opaque()
+	assert.True(t, errors.Is(op, sentinel))
+	assert.False(t, errors.Is(op, xerror.New("other")))
+}
+
+func TestOpaque_Redacted(t *testing.T) {
+	xerror.SetMessageRedactor(map[*regexp.Regexp]string{regexp.MustCompile(`secret-\w+`): "[REDACTED]"})
+	defer xerror.SetMessageRedactor(nil)
+	assert.Equal(t, "token [REDACTED]", xerror.New("token %v", "secret-123").Opaque().Error())
+}
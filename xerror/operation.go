@@ -0,0 +1,16 @@
+package xerror
+
+// WithOperationKey returns a copy of the `Error` with the given operation key, i.e. the caller-supplied identity of the
+// operation that failed (e.g. an idempotency key), so that failures of retries of the same operation can be grouped or
+// deduplicated. Unlike the instance ID, it is shared by all the occurrences of a failure of the operation. Wrapping
+// preserves the key unless the outer error sets its own.
+func (e *xerr) WithOperationKey(key string) Error {
+	x := e.Clone().(*xerr)
+	x.opKey = key
+	return x
+}
+
+// OperationKey returns the operation key associated with the error, or an empty string if not set.
+func (e *xerr) OperationKey() string {
+	return e.opKey
+}
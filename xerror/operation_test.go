@@ -0,0 +1,38 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithOperationKey(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithOperationKey("order-42")
+	assert.Equal(t, "", err.OperationKey())
+	assert.Equal(t, "order-42", cp.OperationKey())
+	assert.Equal(t, "order-42", cp.NewOccurrence().OperationKey())
+}
+
+func TestWithOperationKey_Wrap(t *testing.T) {
+	inner := xerror.New("fmt").WithOperationKey("inner")
+	assert.Equal(t, "inner", xerror.Wrap(inner, "fmt2").OperationKey())
+	assert.Equal(t, "outer", xerror.Wrap(inner, "fmt2").WithOperationKey("outer").OperationKey())
+}
+
+func TestWithOperationKey_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.New("fmt").WithOperationKey("order-42"))
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, "order-42", m["operationKey"])
+
+	e, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "order-42", e.OperationKey())
+
+	buf, err = json.Marshal(xerror.New("fmt"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(buf), "operationKey")
+}
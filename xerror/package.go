@@ -0,0 +1,40 @@
+package xerror
+
+import (
+	"strings"
+)
+
+// FieldPackage is the name of the field set when enabled by `SetPackageField`.
+const FieldPackage = "pkg"
+
+// SetPackageField enables or disables recording the import path of the package that created an error (i.e. the
+// package of the first frame of its stack outside of the Go runtime and of this package, see `Frame.Package`) in the
+// `FieldPackage` field, e.g. to route errors to the logger of the originating package. It is disabled by default to
+// avoid the cost on every error. Errors without such a frame (e.g. created by `NewNoStack`) don't get the field.
+func SetPackageField(enabled bool) {
+	setConfig(func(c *config) {
+		c.packageField = enabled
+	})
+}
+
+// Package returns the import path of the package of the frame's function (e.g. "github.com/user/repo/pkg"), or an
+// empty string if the function is unknown.
+func (f Frame) Package() string {
+	slash := strings.LastIndex(f.Function, "/")
+	if dot := strings.Index(f.Function[slash+1:], "."); dot >= 0 {
+		return f.Function[:slash+1+dot]
+	}
+	return ""
+}
+
+// tagPackage sets the `FieldPackage` field of a new error, if enabled by `SetPackageField`
+func (e *xerr) tagPackage() {
+	if !getConfig().packageField {
+		return
+	}
+	if f, ok := e.topFrame(); ok {
+		if pkg := f.Package(); pkg != "" {
+			e.fields = mergeFields(e.fields, map[string]interface{}{FieldPackage: pkg})
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetPackageField(t *testing.T) {
+	assert.NotContains(t, xerror.New("fmt").Fields(), xerror.FieldPackage)
+
+	xerror.SetPackageField(true)
+	defer xerror.SetPackageField(false)
+
+	const pkg = "github.com/ibrt/go-xerror/xerror_test"
+	assert.Equal(t, pkg, xerror.New("fmt").Fields()[xerror.FieldPackage])
+	assert.Equal(t, pkg, xerror.Wrap(errors.New("ew"), "fmt").Fields()[xerror.FieldPackage])
+	assert.Equal(t, pkg, xerror.Wrap(xerror.New("fmt"), "fmt2").Fields()[xerror.FieldPackage])
+	assert.NotContains(t, xerror.NewNoStack("fmt").Fields(), xerror.FieldPackage)
+}
+
+func TestFrame_Package(t *testing.T) {
+	assert.Equal(t, "main", xerror.Frame{Function: "main.doWork"}.Package())
+	assert.Equal(t, "github.com/user/repo/pkg", xerror.Frame{Function: "github.com/user/repo/pkg.(*T).Method"}.Package())
+	assert.Equal(t, "", xerror.Frame{}.Package())
+}
@@ -0,0 +1,19 @@
+package xerror
+
+import (
+	"fmt"
+)
+
+// ErrorPanic is the message format of errors created by `FromPanic`.
+const ErrorPanic = "panic"
+
+// FromPanic returns an error describing the given value recovered from a panic. If the value is an `error` it is
+// wrapped, so that it remains reachable with `errors.Is` and `errors.As`; otherwise its "%v" representation is used as
+// the wrapped message, and the value itself is stored as a debug object. Call it from the deferred function that
+// recovers the panic: the stack is captured there, so it includes the frames that panicked.
+func FromPanic(r interface{}) Error {
+	if err, ok := r.(error); ok {
+		return Wrap(err, ErrorPanic)
+	}
+	return Wrap(fmt.Errorf("%v", r), ErrorPanic, r)
+}
@@ -0,0 +1,43 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func recoverFrom(fn func()) (err xerror.Error) {
+	defer func() {
+		err = xerror.FromPanic(recover())
+	}()
+	fn()
+	return nil
+}
+
+func panickingFunc() {
+	panic("boom")
+}
+
+func TestFromPanic(t *testing.T) {
+	err := recoverFrom(panickingFunc)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.True(t, xerror.Is(err, xerror.ErrorPanic))
+	assert.Equal(t, []interface{}{"boom"}, err.Debug())
+
+	found := false
+	for _, f := range err.StackFrames() {
+		if f.Function == "github.com/ibrt/go-xerror/xerror_test.panickingFunc" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestFromPanic_Error(t *testing.T) {
+	cause := errors.New("boom")
+	err := recoverFrom(func() { panic(cause) })
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.True(t, errors.Is(err, cause))
+	assert.Empty(t, err.Debug())
+}
@@ -0,0 +1,31 @@
+package xerror
+
+// WithPublicField returns a copy of the `Error` with the given field set and marked as public, i.e. safe to expose to
+// clients. Public fields are returned by `Fields` like the other fields, and also by `PublicFields`. Setting the same
+// field again with `WithField` or `WithFields` makes it internal again.
+func (e *xerr) WithPublicField(key string, value interface{}) Error {
+	x := e.Clone().(*xerr)
+	x.fields = mergeFields(x.fields, map[string]interface{}{key: value})
+	x.pub = clonePublic(x.pub)
+	x.pub[key] = true
+	return x
+}
+
+// PublicFields returns a copy of the fields marked as public by `WithPublicField`, e.g. to include them in responses
+// to clients, while `Fields` also returns the internal ones.
+func (e *xerr) PublicFields() map[string]interface{} {
+	fields := make(map[string]interface{}, len(e.pub))
+	for k := range e.pub {
+		fields[k] = e.fields[k]
+	}
+	return fields
+}
+
+// clonePublic returns a copy of the given set of public field keys
+func clonePublic(pub map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(pub)+1)
+	for k := range pub {
+		c[k] = true
+	}
+	return c
+}
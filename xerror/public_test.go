@@ -0,0 +1,24 @@
+package xerror_test
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithPublicField(t *testing.T) {
+	orig := xerror.New("fmt").WithField("k1", "v1")
+	err := orig.WithPublicField("k2", "v2")
+	assert.Equal(t, map[string]interface{}{"k1": "v1", "k2": "v2"}, err.Fields())
+	assert.Equal(t, map[string]interface{}{"k2": "v2"}, err.PublicFields())
+	assert.Equal(t, map[string]interface{}{}, orig.PublicFields())
+	assert.Equal(t, map[string]interface{}{"k2": "v2"}, xerror.Wrap(err, "fmt2").PublicFields())
+}
+
+func TestWithPublicField_Override(t *testing.T) {
+	err := xerror.New("fmt").WithPublicField("k1", "v1").WithPublicField("k2", "v2")
+	internal := err.WithField("k1", "v3")
+	assert.Equal(t, map[string]interface{}{"k2": "v2"}, internal.PublicFields())
+	assert.Equal(t, map[string]interface{}{"k1": "v1", "k2": "v2"}, err.PublicFields())
+	assert.Equal(t, map[string]interface{}{"k1": "v4", "k2": "v2"}, internal.WithPublicField("k1", "v4").PublicFields())
+}
@@ -0,0 +1,109 @@
+package xerror
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+)
+
+// RedactedSecret is the placeholder that replaces the value of a `Secret` when it is formatted or marshaled.
+const RedactedSecret = "[REDACTED]"
+
+// Secret wraps a sensitive value (e.g. a password or a token) attached as a debug object, so that it never appears in
+// rendered or marshaled errors: it is formatted by `fmt`, whatever the verb, and marshaled to JSON as `RedactedSecret`.
+// The value remains available to code that explicitly reads `Value`.
+type Secret struct {
+	Value interface{}
+}
+
+// String implements the `fmt.Stringer` interface.
+func (s Secret) String() string {
+	return RedactedSecret
+}
+
+// GoString implements the `fmt.GoStringer` interface.
+func (s Secret) GoString() string {
+	return RedactedSecret
+}
+
+// Format implements the `fmt.Formatter` interface, writing `RedactedSecret` for every verb and flag.
+func (s Secret) Format(f fmt.State, verb rune) {
+	io.WriteString(f, RedactedSecret)
+}
+
+// MarshalJSON implements the `json.Marshaler` interface.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedSecret)
+}
+
+// RedactSecrets is a debug redactor, to be passed to `SetDebugRedactor`, that replaces `Secret` (and `*Secret`) debug
+// objects with `RedactedSecret` and returns other objects unchanged.
+func RedactSecrets(v interface{}) interface{} {
+	switch v.(type) {
+	case Secret, *Secret:
+		return RedactedSecret
+	default:
+		return v
+	}
+}
+
+// messageRedactor replaces matches of a pattern with a replacement in rendered messages
+type messageRedactor struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// SetMessageRedactor sets patterns that are replaced in the message every time an error is rendered by `Error` (and
+// therefore by `MarshalJSON`, `GoString` and the `fmt` verbs), e.g. to mask emails or IDs interpolated into messages.
+// Replacements use the `regexp.Regexp.ReplaceAllString` syntax. Patterns are applied in the lexicographic order of
+// their source text, and patterns with the same source text in the lexicographic order of their replacement, so the
+// result does not depend on map iteration order. Passing nil or an empty map disables redaction.
+//
+// Results are not cached: every pattern runs on every render, so keep the set small on hot logging paths.
+func SetMessageRedactor(patterns map[*regexp.Regexp]string) {
+	redactors := make([]messageRedactor, 0, len(patterns))
+	for p, r := range patterns {
+		redactors = append(redactors, messageRedactor{pattern: p, replacement: r})
+	}
+	sort.Slice(redactors, func(i, j int) bool {
+		if pi, pj := redactors[i].pattern.String(), redactors[j].pattern.String(); pi != pj {
+			return pi < pj
+		}
+		return redactors[i].replacement < redactors[j].replacement
+	})
+	setConfig(func(c *config) {
+		c.messageRedactors = redactors
+	})
+}
+
+// redactMessage applies the registered message redactors to the given message
+func redactMessage(msg string) string {
+	for _, r := range getConfig().messageRedactors {
+		msg = r.pattern.ReplaceAllString(msg, r.replacement)
+	}
+	return msg
+}
+
+// SetDebugRedactor sets a function applied to each debug object every time an error is rendered (see `RenderedDebug`),
+// before sorting, depth and length limits, e.g. to mask passwords or tokens attached with `New` or `WithDebug`. It only
+// affects the rendered form: `Debug` still returns the original objects. Passing nil, the default, disables redaction.
+func SetDebugRedactor(fn func(interface{}) interface{}) {
+	setConfig(func(c *config) {
+		c.debugRedactor = fn
+	})
+}
+
+// redactDebug applies the registered debug redactor to the given debug objects
+func redactDebug(dbg []interface{}) []interface{} {
+	fn := getConfig().debugRedactor
+	if fn == nil {
+		return dbg
+	}
+	redacted := make([]interface{}, 0, len(dbg))
+	for _, d := range dbg {
+		redacted = append(redacted, fn(d))
+	}
+	return redacted
+}
@@ -0,0 +1,105 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"testing"
+)
+
+func TestSetMessageRedactor(t *testing.T) {
+	xerror.SetMessageRedactor(map[*regexp.Regexp]string{
+		regexp.MustCompile(`[a-z]+@[a-z]+\.com`): "[email]",
+	})
+	defer xerror.SetMessageRedactor(nil)
+
+	err := xerror.Wrap(xerror.New("user %v not found", "john@example.com"), "lookup failed")
+	assert.Equal(t, "lookup failed: user [email] not found", err.Error())
+	assert.Equal(t, []interface{}{"john@example.com"}, err.Debug())
+
+	buf, err2 := json.Marshal(err)
+	assert.Nil(t, err2)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, "lookup failed: user [email] not found", m["message"])
+}
+
+func TestSetMessageRedactor_DeterministicOrder(t *testing.T) {
+	xerror.SetMessageRedactor(map[*regexp.Regexp]string{
+		regexp.MustCompile(`y`): "z",
+		regexp.MustCompile(`x`): "y",
+	})
+	defer xerror.SetMessageRedactor(nil)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, "z", xerror.New("x").Error())
+	}
+}
+
+func TestSetMessageRedactor_SameSource(t *testing.T) {
+	defer xerror.SetMessageRedactor(nil)
+
+	for i := 0; i < 10; i++ {
+		xerror.SetMessageRedactor(map[*regexp.Regexp]string{
+			regexp.MustCompile(`x`): "c",
+			regexp.MustCompile(`x`): "b",
+			regexp.MustCompile(`x`): "a",
+		})
+		assert.Equal(t, "a", xerror.New("x").Error())
+	}
+}
+
+func TestSetMessageRedactor_Disabled(t *testing.T) {
+	xerror.SetMessageRedactor(nil)
+	assert.Equal(t, "john@example.com", xerror.New("john@example.com").Error())
+}
+
+func TestSecret(t *testing.T) {
+	err := xerror.New("login failed for %v", "john").WithDebug(xerror.Secret{Value: "hunter2"}, &xerror.Secret{Value: "tok"})
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	assert.NotContains(t, string(buf), "hunter2")
+	assert.NotContains(t, string(buf), "tok\"")
+	assert.Contains(t, string(buf), `"debug":["john","[REDACTED]","[REDACTED]"]`)
+	assert.NotContains(t, fmt.Sprintf("%#v", err), "hunter2")
+	assert.Equal(t, "bad password [REDACTED]", xerror.New("bad password %v", xerror.Secret{Value: "hunter2"}).Error())
+	assert.Equal(t, "hunter2", err.Debug()[1].(xerror.Secret).Value)
+}
+
+func TestSecret_Verbs(t *testing.T) {
+	s := xerror.Secret{Value: "hunter2"}
+	assert.Equal(t, "code [REDACTED]", xerror.New("code %d", s).Error())
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d", "%10.2f"} {
+		assert.Equal(t, xerror.RedactedSecret, fmt.Sprintf(verb, s), verb)
+		assert.Equal(t, xerror.RedactedSecret, fmt.Sprintf(verb, &s), verb)
+	}
+}
+
+func TestSetDebugRedactor(t *testing.T) {
+	xerror.SetDebugRedactor(func(v interface{}) interface{} {
+		if m, ok := v.(map[string]string); ok {
+			if _, ok := m["password"]; ok {
+				return "[credentials]"
+			}
+		}
+		return xerror.RedactSecrets(v)
+	})
+	defer xerror.SetDebugRedactor(nil)
+
+	creds := map[string]string{"user": "john", "password": "hunter2"}
+	err := xerror.New("fmt").WithDebug(creds, xerror.Secret{Value: "tok"}, 42)
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	assert.NotContains(t, string(buf), "hunter2")
+	assert.Contains(t, string(buf), `"debug":["[credentials]","[REDACTED]",42]`)
+	assert.Equal(t, creds, err.Debug()[0])
+	assert.Equal(t, []interface{}{"[credentials]", "[REDACTED]", 42}, err.RenderedDebug())
+}
+
+func TestRedactSecrets(t *testing.T) {
+	assert.Equal(t, xerror.RedactedSecret, xerror.RedactSecrets(xerror.Secret{Value: "x"}))
+	assert.Equal(t, xerror.RedactedSecret, xerror.RedactSecrets(&xerror.Secret{Value: "x"}))
+	assert.Equal(t, "x", xerror.RedactSecrets("x"))
+}
@@ -0,0 +1,87 @@
+package xerror
+
+import (
+	"errors"
+)
+
+// FieldAttempts is the name of the field set by `RetriesExhausted`.
+const FieldAttempts = "attempts"
+
+// RetryPolicy describes when failed operations should be retried, see `ShouldRetry`.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. Zero or less means unlimited.
+	MaxAttempts int
+
+	// Retryable, if set, decides whether an error is retryable, replacing the default decision.
+	Retryable func(error) bool
+}
+
+// WithRetryable returns a copy of the `Error` explicitly flagged as retryable (i.e. a transient failure, expected to
+// succeed when retried) or not. The flag survives wrapping, and can be overridden by flagging a wrapping error.
+func (e *xerr) WithRetryable(retryable bool) Error {
+	x := e.Clone().(*xerr)
+	x.retry = &retryable
+	return x
+}
+
+// IsRetryable returns true if the first `Error` in the chain of `err` that was flagged by `WithRetryable` was flagged
+// as retryable, false otherwise (including if no error in the chain was flagged). Since the flag survives wrapping, an
+// error wrapping a retryable error is retryable unless it is flagged otherwise itself.
+func IsRetryable(err error) bool {
+	r, ok := retryableFlag(err)
+	return ok && r
+}
+
+// retryableFlag returns the flag set by `WithRetryable` on the first `Error` in the chain of `err` that has one, and
+// whether one was found
+func retryableFlag(err error) (bool, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if x, ok := err.(*xerr); ok && x.retry != nil {
+			return *x.retry, true
+		}
+	}
+	return false, false
+}
+
+// ShouldRetry is like the package-level `ShouldRetry`, for this error.
+func (e *xerr) ShouldRetry(policy RetryPolicy, attempt int) bool {
+	return ShouldRetry(e, policy, attempt)
+}
+
+// ShouldRetry returns true if the operation that failed with the given error should be retried under the given policy,
+// where `attempt` is the number of attempts made so far (starting from 1). It returns false if `err` is nil, or if
+// `attempt` reached the policy's `MaxAttempts`. Otherwise, if the policy sets `Retryable` it decides; by default the
+// flag set by `WithRetryable` decides (see `IsRetryable`), and if no error in the chain was flagged, errors (or errors
+// in their chain) whose `Timeout` or `Temporary` method returns true are retryable.
+func ShouldRetry(err error, policy RetryPolicy, attempt int) bool {
+	if err == nil {
+		return false
+	}
+	if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+		return false
+	}
+	if policy.Retryable != nil {
+		return policy.Retryable(err)
+	}
+	if r, ok := retryableFlag(err); ok {
+		return r
+	}
+	return isTransient(err)
+}
+
+// RetriesExhausted wraps the last error returned by an operation that is not going to be retried anymore, after the
+// given number of attempts. The returned error records the number of attempts in the `FieldAttempts` field, and is
+// flagged as not retryable (see `WithRetryable`). It returns nil if `last` is nil.
+func RetriesExhausted(attempts int, last error) Error {
+	if last == nil {
+		return nil
+	}
+	return Wrap(last, "giving up after %d attempts", attempts).
+		WithField(FieldAttempts, attempts).
+		WithRetryable(false)
+}
+
+// isTransient returns true if the given error or an error in its chain is a timeout or temporary error
+func isTransient(err error) bool {
+	return IsTimeout(err) || IsTemporary(err)
+}
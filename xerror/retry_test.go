@@ -0,0 +1,94 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+func TestShouldRetry(t *testing.T) {
+	policy := xerror.RetryPolicy{MaxAttempts: 3}
+	err := xerror.Wrap(timeoutErr{}, "fmt")
+	assert.True(t, err.ShouldRetry(policy, 1))
+	assert.True(t, err.ShouldRetry(policy, 2))
+	assert.False(t, err.ShouldRetry(policy, 3))
+	assert.False(t, xerror.Wrap(io.EOF, "fmt").ShouldRetry(policy, 1))
+	assert.False(t, xerror.New("fmt").ShouldRetry(policy, 1))
+}
+
+func TestShouldRetry_Unlimited(t *testing.T) {
+	assert.True(t, xerror.ShouldRetry(timeoutErr{}, xerror.RetryPolicy{}, 1000))
+}
+
+func TestShouldRetry_Predicate(t *testing.T) {
+	policy := xerror.RetryPolicy{
+		MaxAttempts: 2,
+		Retryable: func(err error) bool {
+			return errors.Is(err, io.EOF)
+		},
+	}
+	assert.True(t, xerror.Wrap(io.EOF, "fmt").ShouldRetry(policy, 1))
+	assert.False(t, xerror.Wrap(io.EOF, "fmt").ShouldRetry(policy, 2))
+	assert.False(t, xerror.Wrap(timeoutErr{}, "fmt").ShouldRetry(policy, 1))
+}
+
+func TestShouldRetry_TopLevel(t *testing.T) {
+	policy := xerror.RetryPolicy{MaxAttempts: 3}
+	assert.False(t, xerror.ShouldRetry(nil, policy, 1))
+	assert.True(t, xerror.ShouldRetry(timeoutErr{}, policy, 1))
+	assert.False(t, xerror.ShouldRetry(io.EOF, policy, 1))
+	assert.True(t, xerror.ShouldRetry(xerror.Wrap(xerror.Wrap(timeoutErr{}, "fmt"), "fmt2"), policy, 1))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	last := xerror.Wrap(timeoutErr{}, "fmt")
+	err := xerror.RetriesExhausted(3, last)
+	assert.Equal(t, "giving up after 3 attempts: fmt: "+timeoutErr{}.Error(), err.Error())
+	assert.Equal(t, 3, err.Fields()[xerror.FieldAttempts])
+	assert.False(t, xerror.IsRetryable(err))
+	assert.Equal(t, last.Stack(), err.Stack())
+	assert.True(t, errors.Is(err, timeoutErr{}))
+	assert.True(t, last.ShouldRetry(xerror.RetryPolicy{}, 1))
+	assert.False(t, err.ShouldRetry(xerror.RetryPolicy{}, 1))
+}
+
+func TestRetriesExhausted_Nil(t *testing.T) {
+	assert.Nil(t, xerror.RetriesExhausted(3, nil))
+}
+
+func TestWithRetryable(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.False(t, xerror.IsRetryable(err))
+	assert.True(t, xerror.IsRetryable(err.WithRetryable(true)))
+	assert.False(t, xerror.IsRetryable(err.WithRetryable(false)))
+	assert.True(t, xerror.IsRetryable(xerror.Wrap(err.WithRetryable(true), "fmt2")))
+	assert.True(t, xerror.IsRetryable(fmt.Errorf("wrapped: %w", err.WithRetryable(true))))
+	assert.True(t, xerror.IsRetryable(xerror.Wrap(fmt.Errorf("wrapped: %w", err.WithRetryable(true)), "fmt2")))
+	assert.False(t, xerror.IsRetryable(xerror.Wrap(err.WithRetryable(true), "fmt2").WithRetryable(false)))
+	assert.False(t, xerror.IsRetryable(timeoutErr{}))
+	assert.False(t, xerror.IsRetryable(nil))
+}
+
+func TestWithRetryable_ShouldRetry(t *testing.T) {
+	assert.True(t, xerror.New("fmt").WithRetryable(true).ShouldRetry(xerror.RetryPolicy{}, 1))
+	assert.False(t, xerror.Wrap(timeoutErr{}, "fmt").WithRetryable(false).ShouldRetry(xerror.RetryPolicy{}, 1))
+}
+
+func TestWithRetryable_JSON(t *testing.T) {
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").WithRetryable(false).GoString()), &m))
+	assert.Equal(t, false, m["retryable"])
+
+	e, err := xerror.UnmarshalJSON([]byte(xerror.New("fmt").WithRetryable(true).GoString()))
+	assert.Nil(t, err)
+	assert.True(t, xerror.IsRetryable(e))
+
+	m2 := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").GoString()), &m2))
+	_, ok := m2["retryable"]
+	assert.False(t, ok)
+}
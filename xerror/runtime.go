@@ -0,0 +1,32 @@
+package xerror
+
+import (
+	"runtime"
+)
+
+// FieldRuntime is the name of the field set by `WithRuntimeStats`.
+const FieldRuntime = "runtime"
+
+// RuntimeStats is a snapshot of the state of the Go runtime, attached to errors by `WithRuntimeStats`.
+type RuntimeStats struct {
+	Alloc        uint64 `json:"alloc"`
+	HeapObjects  uint64 `json:"heapObjects"`
+	Sys          uint64 `json:"sys"`
+	NumGC        uint32 `json:"numGC"`
+	NumGoroutine int    `json:"numGoroutine"`
+}
+
+// WithRuntimeStats returns a copy of the `Error` with a `RuntimeStats` snapshot stored as the "runtime" field, e.g. to
+// diagnose resource exhaustion. It must be requested explicitly because reading the memory statistics briefly stops
+// the world.
+func (e *xerr) WithRuntimeStats() Error {
+	mem := runtime.MemStats{}
+	runtime.ReadMemStats(&mem)
+	return e.WithField(FieldRuntime, RuntimeStats{
+		Alloc:        mem.Alloc,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		NumGoroutine: runtime.NumGoroutine(),
+	})
+}
@@ -0,0 +1,33 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWithRuntimeStats(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithRuntimeStats()
+	_, ok := err.Fields()[xerror.FieldRuntime]
+	assert.False(t, ok)
+	stats, ok := cp.Fields()[xerror.FieldRuntime].(xerror.RuntimeStats)
+	assert.True(t, ok)
+	assert.True(t, stats.Alloc > 0)
+	assert.True(t, stats.Sys > 0)
+	assert.True(t, stats.NumGoroutine > 0)
+}
+
+func TestWithRuntimeStats_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.New("fmt").WithRuntimeStats())
+	assert.Nil(t, err)
+	m := struct {
+		Fields map[string]map[string]interface{} `json:"fields"`
+	}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	for _, k := range []string{"alloc", "heapObjects", "sys", "numGC", "numGoroutine"} {
+		_, ok := m.Fields["runtime"][k]
+		assert.True(t, ok, k)
+	}
+}
@@ -0,0 +1,38 @@
+package xerror
+
+// DefaultSeparator is the separator used by default to join the messages of the layers of an error.
+const DefaultSeparator = ": "
+
+// SetMessageSeparator sets the separator used to join the messages of the layers of errors every time they are rendered
+// by `Error` (e.g. " -> " instead of ": "), unless overridden by `WithSeparator`. The separator only affects rendering:
+// `Is` and `Contains` match message formats regardless of it. Passing an empty string restores `DefaultSeparator`.
+func SetMessageSeparator(sep string) {
+	setConfig(func(c *config) {
+		c.messageSeparator = sep
+	})
+}
+
+// WithSeparator returns a copy of the `Error` whose layer messages are joined with the given separator, overriding the
+// one set by `SetMessageSeparator`. Passing an empty string restores the package-level separator. Wrapping preserves
+// the separator.
+func (e *xerr) WithSeparator(sep string) Error {
+	x := e.Clone().(*xerr)
+	x.sep = sep
+	return x
+}
+
+// separator returns the separator used to join the messages of the layers of the error
+func (e *xerr) separator() string {
+	if e.sep != "" {
+		return e.sep
+	}
+	return messageSeparator()
+}
+
+// messageSeparator returns the package-level separator
+func messageSeparator() string {
+	if sep := getConfig().messageSeparator; sep != "" {
+		return sep
+	}
+	return DefaultSeparator
+}
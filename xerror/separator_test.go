@@ -0,0 +1,66 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSetMessageSeparator(t *testing.T) {
+	err := xerror.Wrap(xerror.Wrap(errors.New("ew"), "fmt %v", "p1"), "fmt2")
+	assert.Equal(t, "fmt2: fmt p1: ew", err.Error())
+
+	xerror.SetMessageSeparator(" -> ")
+	defer xerror.SetMessageSeparator("")
+	assert.Equal(t, "fmt2 -> fmt p1 -> ew", err.Error())
+	assert.Equal(t, "fmt3 -> fmt2 -> fmt p1 -> ew", xerror.Wrap(err, "fmt3").Error())
+	assert.True(t, xerror.Is(err, "fmt2"))
+	assert.True(t, err.Contains("fmt %v"))
+
+	xerror.SetMessageSeparator("")
+	assert.Equal(t, "fmt2: fmt p1: ew", err.Error())
+}
+
+func TestWithSeparator(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt1"), "fmt2")
+	cp := err.WithSeparator(" | ")
+	assert.Equal(t, "fmt2: fmt1", err.Error())
+	assert.Equal(t, "fmt2 | fmt1", cp.Error())
+	assert.Equal(t, "fmt3 | fmt2 | fmt1", xerror.Wrap(cp, "fmt3").Error())
+	assert.Equal(t, []string{"fmt2", "fmt1"}, cp.MessageChain())
+
+	xerror.SetMessageSeparator(" -> ")
+	defer xerror.SetMessageSeparator("")
+	assert.Equal(t, "fmt2 | fmt1", cp.Error())
+	assert.Equal(t, "fmt2 -> fmt1", cp.WithSeparator("").Error())
+}
+
+func TestSetMessageSeparator_JSON(t *testing.T) {
+	xerror.SetMessageSeparator(" -> ")
+	defer xerror.SetMessageSeparator("")
+
+	buf, err := json.Marshal(xerror.Wrap(xerror.New("fmt1"), "fmt2"))
+	assert.Nil(t, err)
+	e, err := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "fmt2 -> fmt1", e.Error())
+	assert.Equal(t, []string{"fmt2", "fmt1"}, e.MessageChain())
+}
+
+func TestWithSeparator_JSON(t *testing.T) {
+	err := xerror.Wrap(xerror.New("a: b"), "fmt2").WithSeparator(" | ")
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	assert.Contains(t, string(buf), `"separator":" | "`)
+
+	out, e := xerror.UnmarshalJSON(buf)
+	assert.Nil(t, e)
+	assert.Equal(t, "fmt2 | a: b", out.Error())
+	assert.Equal(t, []string{"fmt2", "a: b"}, out.MessageChain())
+
+	buf, e = json.Marshal(xerror.New("fmt"))
+	assert.Nil(t, e)
+	assert.NotContains(t, string(buf), `"separator"`)
+}
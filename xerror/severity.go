@@ -0,0 +1,104 @@
+package xerror
+
+import (
+	"errors"
+)
+
+// Severity is the severity level of an error, ordered from least to most severe.
+type Severity int
+
+// Known severity levels.
+const (
+	SeverityDebug Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+// DefaultSeverity is the severity of errors that don't set one explicitly.
+const DefaultSeverity = SeverityError
+
+// String returns the lowercase name of the severity level.
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// parseSeverity returns the severity level with the given name, as returned by `String`, or zero if unknown
+func parseSeverity(name string) Severity {
+	for s := SeverityDebug; s <= SeverityFatal; s++ {
+		if s.String() == name {
+			return s
+		}
+	}
+	return 0
+}
+
+// WithSeverity returns a copy of the `Error` with the given severity.
+func (e *xerr) WithSeverity(s Severity) Error {
+	x := e.Clone().(*xerr)
+	x.severity = s
+	return x
+}
+
+// Severity returns the severity of the error, or `DefaultSeverity` if not set.
+func (e *xerr) Severity() Severity {
+	if e.severity == 0 {
+		return DefaultSeverity
+	}
+	return e.severity
+}
+
+// AtLeast returns true if the severity level is the same as or more severe than the given one.
+func (s Severity) AtLeast(t Severity) bool {
+	return s >= t
+}
+
+// AtLeastSeverity returns true if the severity of the error is the same as or more severe than the given one, e.g. to
+// decide whether it should trigger an alert.
+func (e *xerr) AtLeastSeverity(s Severity) bool {
+	return e.Severity().AtLeast(s)
+}
+
+// AtLeastSeverity is like the `AtLeastSeverity` method, but accepts any `error`, looking for an `Error` in its chain.
+// Go errors are treated as having `DefaultSeverity`. It returns false if `err` is nil.
+func AtLeastSeverity(err error, s Severity) bool {
+	return err != nil && severityOf(err).AtLeast(s)
+}
+
+// MoreSevere returns the more severe of the given errors, or `a` if they have the same severity. Go errors are treated
+// as having `DefaultSeverity`, and nil errors as less severe than any error.
+func MoreSevere(a, b error) error {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	if !severityOf(a).AtLeast(severityOf(b)) {
+		return b
+	}
+	return a
+}
+
+// severityOf returns the severity of the given `error`, or `DefaultSeverity` if it is a Go error
+func severityOf(err error) Severity {
+	var x *xerr
+	if errors.As(err, &x) {
+		return x.Severity()
+	}
+	return DefaultSeverity
+}
@@ -0,0 +1,95 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSeverity_Default(t *testing.T) {
+	assert.Equal(t, xerror.SeverityError, xerror.New("fmt").Severity())
+}
+
+func TestWithSeverity(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.WithSeverity(xerror.SeverityWarning)
+	assert.Equal(t, xerror.SeverityError, err.Severity())
+	assert.Equal(t, xerror.SeverityWarning, cp.Severity())
+	assert.Equal(t, xerror.SeverityWarning, xerror.Wrap(cp, "fmt2").Severity())
+}
+
+func TestSeverity_JSON(t *testing.T) {
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").WithSeverity(xerror.SeverityFatal).GoString()), &m))
+	assert.Equal(t, "fatal", m["severity"])
+	assert.Nil(t, json.Unmarshal([]byte(xerror.New("fmt").GoString()), &m))
+	assert.Equal(t, "error", m["severity"])
+}
+
+func TestSeverity_String(t *testing.T) {
+	assert.Equal(t, "debug", xerror.SeverityDebug.String())
+	assert.Equal(t, "info", xerror.SeverityInfo.String())
+	assert.Equal(t, "warning", xerror.SeverityWarning.String())
+	assert.Equal(t, "error", xerror.SeverityError.String())
+	assert.Equal(t, "fatal", xerror.SeverityFatal.String())
+	assert.Equal(t, "unknown", xerror.Severity(0).String())
+}
+
+func TestSeverity_Ordering(t *testing.T) {
+	assert.True(t, xerror.SeverityDebug < xerror.SeverityInfo)
+	assert.True(t, xerror.SeverityInfo < xerror.SeverityWarning)
+	assert.True(t, xerror.SeverityWarning < xerror.SeverityError)
+	assert.True(t, xerror.SeverityError < xerror.SeverityFatal)
+}
+
+func TestMoreSevere(t *testing.T) {
+	warning := xerror.New("warning").WithSeverity(xerror.SeverityWarning)
+	fatal := xerror.New("fatal").WithSeverity(xerror.SeverityFatal)
+	assert.Equal(t, fatal, xerror.MoreSevere(warning, fatal))
+	assert.Equal(t, fatal, xerror.MoreSevere(fatal, warning))
+	assert.Equal(t, fatal, xerror.MoreSevere(xerror.Wrap(warning, "fmt"), fatal))
+}
+
+func TestMoreSevere_Ties(t *testing.T) {
+	a, b := xerror.New("a"), xerror.New("b")
+	assert.Equal(t, a, xerror.MoreSevere(a, b))
+	assert.Equal(t, b, xerror.MoreSevere(b, a))
+}
+
+func TestMoreSevere_Native(t *testing.T) {
+	native := errors.New("native")
+	warning := xerror.New("warning").WithSeverity(xerror.SeverityWarning)
+	fatal := xerror.New("fatal").WithSeverity(xerror.SeverityFatal)
+	assert.Equal(t, native, xerror.MoreSevere(warning, native))
+	assert.Equal(t, native, xerror.MoreSevere(native, warning))
+	assert.Equal(t, fatal, xerror.MoreSevere(native, fatal))
+	assert.Equal(t, native, xerror.MoreSevere(native, xerror.New("error")))
+}
+
+func TestMoreSevere_Nil(t *testing.T) {
+	err := xerror.New("fmt").WithSeverity(xerror.SeverityDebug)
+	assert.Equal(t, err, xerror.MoreSevere(nil, err))
+	assert.Equal(t, err, xerror.MoreSevere(err, nil))
+	assert.Nil(t, xerror.MoreSevere(nil, nil))
+}
+
+func TestAtLeastSeverity(t *testing.T) {
+	err := xerror.New("fmt").WithSeverity(xerror.SeverityWarning)
+	assert.True(t, err.AtLeastSeverity(xerror.SeverityInfo))
+	assert.True(t, err.AtLeastSeverity(xerror.SeverityWarning))
+	assert.False(t, err.AtLeastSeverity(xerror.SeverityError))
+	assert.True(t, xerror.New("fmt").AtLeastSeverity(xerror.DefaultSeverity))
+	assert.True(t, xerror.SeverityFatal.AtLeast(xerror.SeverityError))
+	assert.False(t, xerror.SeverityDebug.AtLeast(xerror.SeverityInfo))
+}
+
+func TestAtLeastSeverity_TopLevel(t *testing.T) {
+	err := xerror.New("fmt").WithSeverity(xerror.SeverityFatal)
+	assert.True(t, xerror.AtLeastSeverity(fmt.Errorf("wrapped: %w", err), xerror.SeverityFatal))
+	assert.True(t, xerror.AtLeastSeverity(errors.New("native"), xerror.SeverityError))
+	assert.False(t, xerror.AtLeastSeverity(errors.New("native"), xerror.SeverityFatal))
+	assert.False(t, xerror.AtLeastSeverity(nil, xerror.SeverityDebug))
+}
@@ -0,0 +1,76 @@
+package xerror
+
+import (
+	"log/slog"
+	"sort"
+)
+
+// Keys of the attributes describing errors in `log/slog` records.
+const (
+	SlogKeyMessage  = "message"
+	SlogKeyCode     = "code"
+	SlogKeySeverity = "severity"
+	SlogKeyDebug    = "debug"
+	SlogKeyStack    = "stack"
+	SlogKeyFields   = "fields"
+)
+
+// SlogAttrs returns the message, code (if set), severity, stack (outside of production mode, see
+// `SetProductionMode`), and fields (sorted by key, nested in a `SlogKeyFields` group) of the error as a flat slice of
+// attributes, e.g. for `logger.Error("failed", err.SlogAttrs()...)`. The keys are the same as in the group returned by
+// `LogValue`.
+func (e *xerr) SlogAttrs() []slog.Attr {
+	attrs := e.slogHeader(5)
+	if stack := e.Stack(); len(stack) > 0 && !ProductionMode() {
+		attrs = append(attrs, slog.Any(SlogKeyStack, stack))
+	}
+	if fields, ok := e.slogFields(); ok {
+		attrs = append(attrs, fields)
+	}
+	return attrs
+}
+
+// LogValue implements the `slog.LogValuer` interface, so that `slog.Error("failed", "err", err)` logs the error as a
+// group: the message, code (if set) and severity, followed by the debug objects (as returned by `RenderedDebug`), the
+// stack frames as strings, and the fields (sorted by key) nested in a `SlogKeyFields` group so that they can't collide
+// with the other keys, each omitted if empty.
+func (e *xerr) LogValue() slog.Value {
+	attrs := e.slogHeader(6)
+	if len(e.dbg) > 0 {
+		attrs = append(attrs, slog.Any(SlogKeyDebug, e.RenderedDebug()))
+	}
+	if stack := e.Stack(); len(stack) > 0 {
+		attrs = append(attrs, slog.Any(SlogKeyStack, stack))
+	}
+	if fields, ok := e.slogFields(); ok {
+		attrs = append(attrs, fields)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// slogHeader returns the message, code (if set), and severity attributes, in a slice with the given capacity
+func (e *xerr) slogHeader(capacity int) []slog.Attr {
+	attrs := make([]slog.Attr, 0, capacity)
+	attrs = append(attrs, slog.String(SlogKeyMessage, e.Error()))
+	if code := e.Code(); code != "" {
+		attrs = append(attrs, slog.String(SlogKeyCode, code))
+	}
+	return append(attrs, slog.String(SlogKeySeverity, e.Severity().String()))
+}
+
+// slogFields returns the fields, sorted by key, as a `SlogKeyFields` group, or false if there are none
+func (e *xerr) slogFields() (slog.Attr, bool) {
+	if len(e.fields) == 0 {
+		return slog.Attr{}, false
+	}
+	keys := make([]string, 0, len(e.fields))
+	for k := range e.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]slog.Attr, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, slog.Any(k, e.fields[k]))
+	}
+	return slog.Attr{Key: SlogKeyFields, Value: slog.GroupValue(fields...)}, true
+}
@@ -0,0 +1,123 @@
+package xerror_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogAttrs(t *testing.T) {
+	err := xerror.NewWithFields("fmt", map[string]interface{}{"k2": "v2", "k1": 1}).
+		WithCode("CODE").
+		WithSeverity(xerror.SeverityWarning)
+	assert.Equal(t, []slog.Attr{
+		slog.String("message", "fmt"),
+		slog.String("code", "CODE"),
+		slog.String("severity", "warning"),
+		slog.Group("fields", slog.Any("k1", 1), slog.Any("k2", "v2")),
+	}, err.SlogAttrs())
+}
+
+func TestSlogAttrs_NoCode(t *testing.T) {
+	assert.Equal(t, []slog.Attr{
+		slog.String("message", "fmt"),
+		slog.String("severity", "error"),
+	}, xerror.New("fmt").SlogAttrs())
+}
+
+func TestSlogAttrs_Logger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.LogAttrs(context.Background(), slog.LevelError, "failed", xerror.New("fmt").WithField("k", "v").SlogAttrs()...)
+	assert.Equal(t, "level=ERROR msg=failed message=fmt severity=error fields.k=v\n", buf.String())
+}
+
+func TestSlogAttrs_CollidingField(t *testing.T) {
+	attrs := xerror.New("fmt").WithField("message", "m").WithField("severity", "s").SlogAttrs()
+	assert.Equal(t, []slog.Attr{
+		slog.String("message", "fmt"),
+		slog.String("severity", "error"),
+		slog.Group("fields", slog.Any("message", "m"), slog.Any("severity", "s")),
+	}, attrs)
+}
+
+func TestSlogAttrs_Stack(t *testing.T) {
+	err := xerror.New("fmt")
+	for _, a := range err.SlogAttrs() {
+		assert.NotEqual(t, "stack", a.Key)
+	}
+
+	xerror.SetProductionMode(false)
+	defer xerror.SetProductionMode(true)
+	attrs := err.SlogAttrs()
+	assert.Equal(t, slog.Any("stack", err.Stack()), attrs[len(attrs)-1])
+}
+
+func TestLogValue(t *testing.T) {
+	xerror.SetStackCaptureEnabled(false)
+	defer xerror.SetStackCaptureEnabled(true)
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Error("failed", "err", xerror.New("fmt %v", "p1", xerror.Secret{Value: "pw"}).WithCode("CODE"))
+	assert.Equal(t, "level=ERROR msg=failed err.message=\"fmt p1\" err.code=CODE err.severity=error err.debug=\"[p1 [REDACTED]]\"\n", buf.String())
+}
+
+func TestLogValue_Stack(t *testing.T) {
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "err", xerror.New("fmt").WithField("k", "v"))
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &m))
+	e := m["err"].(map[string]interface{})
+	assert.Equal(t, "fmt", e["message"])
+	assert.Equal(t, map[string]interface{}{"k": "v"}, e["fields"])
+	assert.NotContains(t, e, "debug")
+	assert.Regexp(t, `slog_test\.go:\d+ \(.*TestLogValue_Stack\)$`, e["stack"].([]interface{})[0])
+}
+
+func TestLogValue_CollidingField(t *testing.T) {
+	xerror.SetStackCaptureEnabled(false)
+	defer xerror.SetStackCaptureEnabled(true)
+
+	buf := &bytes.Buffer{}
+	err := xerror.New("fmt").WithCode("CODE").WithField("code", "user").WithField("stack", "s")
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", "err", err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &m))
+	e := m["err"].(map[string]interface{})
+	assert.Equal(t, "CODE", e["code"])
+	assert.NotContains(t, e, "stack")
+	assert.Equal(t, map[string]interface{}{"code": "user", "stack": "s"}, e["fields"])
+}
+
+func TestLogValue_DebugValueMaxLen(t *testing.T) {
+	xerror.SetDebugValueMaxLen(5)
+	defer xerror.SetDebugValueMaxLen(0)
+
+	value := xerror.New("fmt", "abcdefgh").LogValue()
+	for _, a := range value.Group() {
+		if a.Key == "debug" {
+			assert.Equal(t, []interface{}{"abcde…"}, a.Value.Any())
+			return
+		}
+	}
+	assert.Fail(t, "missing debug attr")
+}
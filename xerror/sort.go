@@ -0,0 +1,46 @@
+package xerror
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SetDebugSortKey sets a function used to sort debug objects before they are rendered by `MarshalJSON` (and therefore
+// `GoString`), which is useful for golden tests that include debug objects coming from maps or concurrent sources.
+// Debug objects are ordered by the string returned for each of them; objects with equal keys keep their insertion
+// order. `Debug` is not affected. Passing nil disables sorting, which is the default. See `DebugStringKey` for a
+// built-in key function.
+func SetDebugSortKey(fn func(interface{}) string) {
+	setConfig(func(c *config) {
+		c.debugSortKey = fn
+	})
+}
+
+// DebugStringKey is a debug sort key function that returns the debug object formatted with "%v".
+func DebugStringKey(v interface{}) string {
+	return fmt.Sprintf("%v", v)
+}
+
+// sortDebug returns a sorted copy of the debug objects if a sort key is configured, the debug objects otherwise
+func sortDebug(dbg []interface{}) []interface{} {
+	fn := getConfig().debugSortKey
+	if fn == nil {
+		return dbg
+	}
+	keys := make([]string, len(dbg))
+	for i, d := range dbg {
+		keys[i] = fn(d)
+	}
+	idx := make([]int, len(dbg))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		return keys[idx[i]] < keys[idx[j]]
+	})
+	sorted := make([]interface{}, len(dbg))
+	for i, j := range idx {
+		sorted[i] = dbg[j]
+	}
+	return sorted
+}
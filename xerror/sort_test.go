@@ -0,0 +1,49 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func debugJSON(t *testing.T, err xerror.Error) []interface{} {
+	buf, e := json.Marshal(err)
+	assert.Nil(t, e)
+	m := struct {
+		Debug []interface{} `json:"debug"`
+	}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	return m.Debug
+}
+
+func TestSetDebugSortKey_Disabled(t *testing.T) {
+	err := xerror.New("fmt", "c", "a", "b")
+	assert.Equal(t, []interface{}{"c", "a", "b"}, debugJSON(t, err))
+}
+
+func TestSetDebugSortKey(t *testing.T) {
+	xerror.SetDebugSortKey(xerror.DebugStringKey)
+	defer xerror.SetDebugSortKey(nil)
+
+	err := xerror.New("fmt", "c", "a", "b")
+	assert.Equal(t, []interface{}{"a", "b", "c"}, debugJSON(t, err))
+	assert.Equal(t, []interface{}{"c", "a", "b"}, err.Debug())
+}
+
+func TestSetDebugSortKey_Ties(t *testing.T) {
+	xerror.SetDebugSortKey(func(v interface{}) string {
+		return v.(map[string]interface{})["k"].(string)
+	})
+	defer xerror.SetDebugSortKey(nil)
+
+	err := xerror.New("fmt",
+		map[string]interface{}{"k": "b", "n": 1.0},
+		map[string]interface{}{"k": "a", "n": 2.0},
+		map[string]interface{}{"k": "b", "n": 3.0})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"k": "a", "n": 2.0},
+		map[string]interface{}{"k": "b", "n": 1.0},
+		map[string]interface{}{"k": "b", "n": 3.0},
+	}, debugJSON(t, err))
+}
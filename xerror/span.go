@@ -0,0 +1,25 @@
+package xerror
+
+// SpanContext identifies the trace span in which an error occurred by its hex-encoded W3C trace and span IDs. It
+// allows carrying tracing identifiers without depending on a tracing library; package xotel converts it from and to
+// OpenTelemetry span contexts.
+type SpanContext struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+// WithSpanContext returns a copy of the `Error` associated with the given span context. Wrapping preserves the span
+// context unless the outer error sets its own.
+func (e *xerr) WithSpanContext(sc SpanContext) Error {
+	x := e.Clone().(*xerr)
+	x.spanContext = &sc
+	return x
+}
+
+// SpanContext returns the span context associated with the error, and whether it was set.
+func (e *xerr) SpanContext() (SpanContext, bool) {
+	if e.spanContext == nil {
+		return SpanContext{}, false
+	}
+	return *e.spanContext, true
+}
@@ -0,0 +1,47 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testSpanContext = xerror.SpanContext{
+	TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+	SpanID:  "00f067aa0ba902b7",
+}
+
+func TestWithSpanContext(t *testing.T) {
+	err := xerror.New("fmt")
+	_, ok := err.SpanContext()
+	assert.False(t, ok)
+
+	sc, ok := err.WithSpanContext(testSpanContext).SpanContext()
+	assert.True(t, ok)
+	assert.Equal(t, testSpanContext, sc)
+	_, ok = err.SpanContext()
+	assert.False(t, ok)
+}
+
+func TestWithSpanContext_Wrap(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt").WithSpanContext(testSpanContext), "fmt2")
+	sc, ok := err.SpanContext()
+	assert.True(t, ok)
+	assert.Equal(t, testSpanContext, sc)
+
+	other := xerror.SpanContext{TraceID: testSpanContext.TraceID, SpanID: "b7ad6b7169203331"}
+	sc, _ = err.WithSpanContext(other).SpanContext()
+	assert.Equal(t, other, sc)
+}
+
+func TestWithSpanContext_JSON(t *testing.T) {
+	buf, err := json.Marshal(xerror.New("fmt").WithSpanContext(testSpanContext))
+	assert.Nil(t, err)
+	m := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, map[string]interface{}{
+		"traceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":  "00f067aa0ba902b7",
+	}, m["spanContext"])
+}
@@ -0,0 +1,396 @@
+package xerror
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultMaxStackDepth is the maximum number of frames captured in stacks, unless changed by SetMaxStackDepth.
+	DefaultMaxStackDepth = 100
+
+	// parentStackMarker separates an error's own stack from the stack of the site that launched its goroutine
+	parentStackMarker = "--- goroutine launched from ---"
+
+	// appendedStackMarker separates an error's own stack from externally captured frames appended by AppendStack
+	appendedStackMarker = "--- continued from ---"
+)
+
+// pkgPath is the import path of this package, used to recognize its own frames
+var pkgPath = reflect.TypeOf(xerr{}).PkgPath()
+
+// Frame is a single frame of a stack trace. Boundary frames, such as the one separating a goroutine stack from its
+// parent stack, have no file and carry their marker as `Function`. Frames of materialized errors have a zero PC.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	PC       uintptr
+
+	// Entry is the entry PC of the function, only recorded if enabled by `SetFrameEntryCapture`.
+	Entry uintptr
+}
+
+// String formats the frame as "file:line (function)", or "file:line (0xpc)" if the function is unknown, or "file:line"
+// if neither is known, or returns the marker of a boundary frame.
+func (f Frame) String() string {
+	switch {
+	case f.isBoundary():
+		return f.Function
+	case f.Function != "":
+		return f.File + ":" + strconv.Itoa(f.Line) + " (" + f.Function + ")"
+	case f.PC != 0:
+		return f.File + ":" + strconv.Itoa(f.Line) + " (0x" + strconv.FormatUint(uint64(f.PC), 16) + ")"
+	default:
+		return f.File + ":" + strconv.Itoa(f.Line)
+	}
+}
+
+// isBoundary returns true if the frame is a boundary marker rather than an actual frame
+func (f Frame) isBoundary() bool {
+	return f.PC == 0 && f.File == ""
+}
+
+// isInternal returns true if the frame belongs to the Go runtime or to this package
+func (f Frame) isInternal() bool {
+	return strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, pkgPath+".")
+}
+
+// stackTrace is the stack of an error. Stacks are captured as raw program counters, which are only resolved to frames
+// the first time they are needed (e.g. by `Stack`, `StackFrames`, or `MarshalJSON`), so that errors whose stack is
+// never looked at don't pay for the resolution. Stacks built from frames (e.g. by `Materialize`) are resolved from the
+// start. A stack is never modified once resolved, so that copies of an error can share it.
+type stackTrace struct {
+	once   sync.Once
+	pcs    []uintptr
+	depth  int
+	exact  bool
+	frames []Frame
+}
+
+// resolvedStack returns a stack trace holding the given resolved frames
+func resolvedStack(frames []Frame) *stackTrace {
+	s := &stackTrace{frames: frames}
+	s.once.Do(func() {}) // nothing to resolve
+	return s
+}
+
+// resolve returns the frames of the stack trace, resolving its program counters if needed
+func (s *stackTrace) resolve() []Frame {
+	if s == nil {
+		return nil
+	}
+	s.once.Do(func() {
+		if s.exact {
+			s.frames = resolveFrames(s.pcs)
+		} else {
+			s.frames = trimStack(resolveFrames(s.pcs), s.depth)
+		}
+		s.pcs = nil
+	})
+	return s.frames
+}
+
+// frames returns the resolved frames of the stack of the error, which must not be modified
+func (e *xerr) frames() []Frame {
+	return e.stack.resolve()
+}
+
+// GoStack captures the stack at the call site, to be passed to a goroutine and later attached to its errors with
+// `WrapWithParentStack`. Call it right before the `go` statement.
+func GoStack() []uintptr {
+	if getConfig().noStackCapture {
+		return nil
+	}
+	return callers(3, maxStackDepth())
+}
+
+// WrapWithParentStack is like `Wrap`, but appends the given parent stack (as returned by `GoStack`) to the stack of the
+// returned error, producing a logical trace across the goroutine boundary. The two stacks are separated by a marker
+// frame.
+func WrapWithParentStack(err error, parent []uintptr, format string, v ...interface{}) Error {
+	xerr := Wrap(err, format, v...).(*xerr)
+	xerr.appendStack(parentStackMarker, parent)
+	return xerr
+}
+
+// AppendStack returns a copy of the `Error` with the given externally captured program counters (e.g. from
+// `GoStack`, called where an asynchronous operation was scheduled) appended to its stack, reconstructing the logical
+// causality across callbacks and event loops. The two stacks are separated by a marker frame, rendered as
+// "--- continued from ---".
+func (e *xerr) AppendStack(pcs []uintptr) Error {
+	x := e.Clone().(*xerr)
+	x.appendStack(appendedStackMarker, pcs)
+	return x
+}
+
+// appendStack appends a boundary frame with the given marker, followed by the resolved given program counters
+func (e *xerr) appendStack(marker string, pcs []uintptr) {
+	frames := append(e.StackFrames(), Frame{Function: marker})
+	e.stack = resolvedStack(append(frames, resolveFrames(pcs)...))
+}
+
+// NewNoStack is like `New`, but doesn't capture a stack trace. Use it on hot paths where the cost of capturing the
+// stack is not justified, e.g. for expected errors that are handled right away.
+func NewNoStack(format string, v ...interface{}) Error {
+	v = nilToEmpty(v)
+	format = internFormat(normalizeFormat(format))
+	return newXerr(format, safeSprintf(format, v), v, nil)
+}
+
+// NewWithSkip is like `New`, but skips the given number of frames at the top of the stack, so that helper constructors
+// wrapping it can make the stack start at their own caller. The frames of the runtime and of the stack capture itself
+// are always skipped: with a skip of 0 the stack starts with the frame of `NewWithSkip`; with a skip of 1 it starts
+// with the caller of `NewWithSkip` (e.g. the helper), with a skip of 2 with the caller of the helper, and so on. Unlike
+// for `New`, the frames of this package are not skipped, so that each frame counts for one.
+func NewWithSkip(skip int, format string, v ...interface{}) Error {
+	v = nilToEmpty(v)
+	format = internFormat(normalizeFormat(format))
+	return newXerr(format, safeSprintf(format, v), v, captureExactStack(3+skip))
+}
+
+// HasStack returns true if the given `error` is of type `Error` and carries at least one stack frame, false otherwise.
+func HasStack(err error) bool {
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.HasStack()
+	}
+	return false
+}
+
+// GroupByTopFrame buckets the given errors by the function name of their top application frame, i.e. the first frame
+// outside of the Go runtime and of this package. Go errors and errors without such a frame are grouped under
+// "unknown". Nil errors are skipped.
+func GroupByTopFrame(errs []error) map[string][]error {
+	groups := map[string][]error{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		key := "unknown"
+		if xerr, ok := err.(*xerr); ok {
+			if f, ok := xerr.topFrame(); ok {
+				key = f.Function
+			}
+		}
+		groups[key] = append(groups[key], err)
+	}
+	return groups
+}
+
+// FormatStack renders the given error for logs or debug output as a header line with its full message, followed by the
+// stack of the first `Error` in its chain, one frame per line, indented with a tab. If `trimInternal` is true, frames
+// belonging to the Go runtime or to this package are omitted, so that the first frame is in the caller's code. Go
+// errors without an `Error` in their chain are rendered as the header line alone. It returns an empty string if `err`
+// is nil.
+func FormatStack(err error, trimInternal bool) string {
+	if err == nil {
+		return ""
+	}
+	b := &strings.Builder{}
+	b.WriteString(err.Error())
+	var x *xerr
+	if errors.As(err, &x) {
+		for _, f := range x.frames() {
+			if trimInternal && !f.isBoundary() && f.isInternal() {
+				continue
+			}
+			b.WriteString("\n\t")
+			b.WriteString(f.String())
+		}
+	}
+	return b.String()
+}
+
+// Stack returns the stack trace associated with the error.
+func (e *xerr) Stack() []string {
+	frames := e.frames()
+	stack := make([]string, 0, len(frames))
+	for _, f := range frames {
+		stack = append(stack, f.String())
+	}
+	return stack
+}
+
+// HasStack returns true if the error carries at least one stack frame, false otherwise (e.g. if it was created by
+// `NewNoStack`). Boundary markers don't count as frames.
+func (e *xerr) HasStack() bool {
+	for _, f := range e.frames() {
+		if !f.isBoundary() {
+			return true
+		}
+	}
+	return false
+}
+
+// Materialize returns a copy of the `Error` whose stack frames keep only their resolved file, line, and function, with
+// program counters (including entry PCs) discarded, so that it can be serialized and shipped to another process where
+// the PCs would be meaningless. The stack is resolved first if it wasn't already. Materialization is one-way: the PCs
+// can't be recovered from a materialized error.
+func (e *xerr) Materialize() Error {
+	x := e.Clone().(*xerr)
+	frames := x.StackFrames()
+	for i := range frames {
+		frames[i].PC = 0
+		frames[i].Entry = 0
+	}
+	x.stack = resolvedStack(frames)
+	return x
+}
+
+// StackFrames returns the stack trace associated with the error as structured frames.
+func (e *xerr) StackFrames() []Frame {
+	frames := e.frames()
+	return append(make([]Frame, 0, len(frames)), frames...)
+}
+
+// StackContainsFile returns true if the path of any frame of the stack contains the given substring, false otherwise
+// (including when the stack is empty).
+func (e *xerr) StackContainsFile(substr string) bool {
+	for _, f := range e.frames() {
+		if !f.isBoundary() && strings.Contains(f.File, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// topFrame returns the first frame outside of the Go runtime and of this package, if any
+func (e *xerr) topFrame() (Frame, bool) {
+	for _, f := range e.frames() {
+		if !f.isBoundary() && !f.isInternal() {
+			return f, true
+		}
+	}
+	return Frame{}, false
+}
+
+// maxOwnFrames bounds the number of frames of this package that can precede the call site of the user in a stack, so
+// that stacks can be captured deep enough to still reach the maximum depth once they are trimmed
+const maxOwnFrames = 8
+
+// newStack returns the stack of the caller, starting at the call site of the user (see `captureStack`)
+func newStack() *stackTrace {
+	return captureStack(4)
+}
+
+// captureStack returns the unresolved stack, skipping the given number of frames (as `runtime.Callers` would, counting
+// the frames of the stack capture itself). When resolved, the stack also skips the leading frames of this package, so
+// that it starts at the call site of the user whichever constructor (e.g. `New` or `Wrap`) was called.
+func captureStack(skip int) *stackTrace {
+	if getConfig().noStackCapture {
+		return nil
+	}
+	depth := maxStackDepth()
+	return &stackTrace{pcs: callers(skip, depth+maxOwnFrames), depth: depth}
+}
+
+// captureExactStack is like `captureStack`, but the stack is not trimmed: it starts exactly after the skipped frames
+func captureExactStack(skip int) *stackTrace {
+	if getConfig().noStackCapture {
+		return nil
+	}
+	return &stackTrace{pcs: callers(skip, maxStackDepth()), exact: true}
+}
+
+// trimStack returns the given frames without the leading ones that belong to this package, truncated to `depth`
+func trimStack(frames []Frame, depth int) []Frame {
+	frames = trimOwnFrames(frames)
+	if len(frames) > depth {
+		frames = frames[:depth]
+	}
+	return frames
+}
+
+// trimOwnFrames returns the given frames without the leading ones that belong to this package
+func trimOwnFrames(frames []Frame) []Frame {
+	for len(frames) > 0 && strings.HasPrefix(frames[0].Function, pkgPath+".") {
+		frames = frames[1:]
+	}
+	return frames
+}
+
+// SetStackCaptureEnabled enables or disables capturing stacks globally. When disabled, `New`, `Wrap`, and the other
+// constructors create errors with an empty stack, and `GoStack` returns no program counters. This is a global trade-off
+// between debuggability and throughput, e.g. for latency-sensitive services using errors for control flow, where stack
+// capture dominates the cost of creating errors; prefer `NewNoStack` to skip capture only on specific hot paths. Stack
+// capture is enabled by default.
+func SetStackCaptureEnabled(enabled bool) {
+	setConfig(func(c *config) {
+		c.noStackCapture = !enabled
+	})
+}
+
+// SetMaxStackDepth sets the maximum number of frames captured in stacks (e.g. by `New`, `Wrap`, and `GoStack`); deeper
+// stacks are truncated. For `GoStack` the limit applies to the captured program counters, so inlined calls may add a
+// few frames when they are resolved. Passing 0 or less restores `DefaultMaxStackDepth`.
+func SetMaxStackDepth(n int) {
+	setConfig(func(c *config) {
+		c.maxStackDepth = n
+	})
+}
+
+// maxStackDepth returns the configured maximum number of frames captured in stacks
+func maxStackDepth() int {
+	if n := getConfig().maxStackDepth; n > 0 {
+		return n
+	}
+	return DefaultMaxStackDepth
+}
+
+// callers returns at most `depth` program counters of the stack, skipping the given number of frames
+func callers(skip, depth int) []uintptr {
+	var buf [DefaultMaxStackDepth + maxOwnFrames]uintptr
+	var pcs []uintptr
+	if depth <= len(buf) {
+		pcs = buf[:depth]
+	} else {
+		pcs = make([]uintptr, depth)
+	}
+	n := runtime.Callers(skip, pcs)
+	return append(make([]uintptr, 0, n), pcs[:n]...)
+}
+
+// SetFrameEntryCapture enables or disables recording the entry PC of the function of each stack frame, alongside the
+// call PC, e.g. for post-mortem tools symbolizing frames (and possibly their arguments) offline with DWARF tooling. It
+// is disabled by default because of the extra storage. Capture is best effort: PCs are only meaningful for the exact
+// binary that produced them, argument values are never captured, and inlined functions report the entry PC of the
+// function they were inlined into. Since stacks are resolved when they are first needed, the setting in effect at that
+// time applies.
+func SetFrameEntryCapture(enabled bool) {
+	setConfig(func(c *config) {
+		c.frameEntryCapture = enabled
+	})
+}
+
+// resolveFrames resolves the given program counters to frames, expanding inlined calls to logical frames
+func resolveFrames(pcs []uintptr) []Frame {
+	entries := getConfig().frameEntryCapture
+	frames := make([]Frame, 0, len(pcs))
+	if len(pcs) == 0 {
+		return frames
+	}
+	it := runtime.CallersFrames(pcs)
+	for {
+		rf, more := it.Next()
+		if rf.PC != 0 {
+			f := Frame{
+				File:     rf.File,
+				Line:     rf.Line,
+				Function: rf.Function,
+				PC:       rf.PC,
+			}
+			if entries {
+				f.Entry = rf.Entry
+			}
+			frames = append(frames, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
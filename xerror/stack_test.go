@@ -0,0 +1,360 @@
+package xerror_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var frameRegexp = regexp.MustCompile(`^.+\.(go|s):\d+ \([^ ]+\)$`)
+
+func TestStack_Format(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.True(t, len(err.Stack()) > 0)
+	for _, frame := range err.Stack() {
+		assert.Regexp(t, frameRegexp, frame)
+	}
+}
+
+func TestStack_FunctionNames(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.Regexp(t, `^.+/stack_test\.go:\d+ \(github\.com/ibrt/go-xerror/xerror_test\.TestStack_FunctionNames\)$`, err.Stack()[0])
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestStack_FunctionNames", err.StackFrames()[0].Function)
+	assert.Equal(t, "file.go:42 (0x2a)", xerror.Frame{File: "file.go", Line: 42, PC: 42}.String())
+	assert.Equal(t, "file.go:42", xerror.Frame{File: "file.go", Line: 42}.String())
+}
+
+func TestGoStack(t *testing.T) {
+	stack := xerror.GoStack()
+	assert.True(t, len(stack) > 0)
+}
+
+func TestWrapWithParentStack(t *testing.T) {
+	parent := xerror.GoStack()
+	ch := make(chan xerror.Error)
+	go func() {
+		ch <- xerror.WrapWithParentStack(errors.New("ew"), parent, "fmt %v", "p1")
+	}()
+	err := <-ch
+	assert.Equal(t, "fmt p1: ew", err.Error())
+	assert.Equal(t, []interface{}{"p1"}, err.Debug())
+
+	marker := -1
+	for i, frame := range err.Stack() {
+		if frame == "--- goroutine launched from ---" {
+			marker = i
+			continue
+		}
+		assert.Regexp(t, frameRegexp, frame)
+	}
+	assert.True(t, marker > 0)
+	assert.Equal(t, len(parent), len(err.Stack())-marker-1)
+}
+
+func TestStackFrames(t *testing.T) {
+	err := xerror.New("fmt")
+	frames := err.StackFrames()
+	assert.Equal(t, len(err.Stack()), len(frames))
+	for i, f := range frames {
+		assert.Equal(t, err.Stack()[i], f.String())
+		assert.NotEqual(t, "", f.Function)
+	}
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestStackFrames", frames[0].Function)
+}
+
+func newFromHelper() error {
+	return xerror.Wrap(errors.New("ew"), "fmt")
+}
+
+func TestGroupByTopFrame(t *testing.T) {
+	native := errors.New("ew")
+	e1 := xerror.New("fmt")
+	e2 := newFromHelper()
+	e3 := newFromHelper()
+	groups := xerror.GroupByTopFrame([]error{e1, e2, nil, native, e3})
+	assert.Equal(t, map[string][]error{
+		"github.com/ibrt/go-xerror/xerror_test.TestGroupByTopFrame": {e1},
+		"github.com/ibrt/go-xerror/xerror_test.newFromHelper":       {e2, e3},
+		"unknown": {native},
+	}, groups)
+}
+
+func TestStackContainsFile(t *testing.T) {
+	err := xerror.New("fmt")
+	assert.True(t, err.StackContainsFile("xerror/stack_test.go"))
+	assert.False(t, err.StackContainsFile("xerror/error.go"))
+	assert.False(t, err.StackContainsFile("no_such_file.go"))
+}
+
+func TestStackContainsFile_ParentStack(t *testing.T) {
+	err := xerror.WrapWithParentStack(errors.New("ew"), nil, "fmt")
+	assert.False(t, err.StackContainsFile("---"))
+}
+
+func TestNewNoStack(t *testing.T) {
+	err := xerror.NewNoStack("fmt %v", "p1", "d1")
+	assert.Equal(t, "fmt p1", err.Error())
+	assert.Equal(t, []interface{}{"p1", "d1"}, err.Debug())
+	assert.Empty(t, err.Stack())
+	assert.Empty(t, xerror.Wrap(err, "fmt2").Stack())
+}
+
+func TestHasStack(t *testing.T) {
+	assert.True(t, xerror.New("fmt").HasStack())
+	assert.False(t, xerror.NewNoStack("fmt").HasStack())
+	assert.False(t, xerror.Wrap(xerror.NewNoStack("fmt"), "fmt2").HasStack())
+	assert.True(t, xerror.Wrap(errors.New("ew"), "fmt").HasStack())
+}
+
+func TestHasStack_TopLevel(t *testing.T) {
+	assert.True(t, xerror.HasStack(xerror.New("fmt")))
+	assert.False(t, xerror.HasStack(xerror.NewNoStack("fmt")))
+	assert.False(t, xerror.HasStack(errors.New("ew")))
+	assert.False(t, xerror.HasStack(nil))
+}
+
+func TestMaterialize(t *testing.T) {
+	err := xerror.WrapWithParentStack(errors.New("ew"), xerror.GoStack(), "fmt")
+	m := err.Materialize()
+	assert.Equal(t, err.Error(), m.Error())
+	assert.True(t, m.HasStack())
+	assert.Len(t, m.StackFrames(), len(err.StackFrames()))
+	for i, f := range m.StackFrames() {
+		orig := err.StackFrames()[i]
+		assert.Equal(t, uintptr(0), f.PC)
+		assert.Equal(t, orig.File, f.File)
+		assert.Equal(t, orig.Line, f.Line)
+		assert.Equal(t, orig.Function, f.Function)
+		assert.NotRegexp(t, `0x[0-9a-f]+`, f.String())
+	}
+	assert.Contains(t, m.Stack(), "--- goroutine launched from ---")
+	assert.NotEqual(t, uintptr(0), err.StackFrames()[0].PC)
+}
+
+func TestAppendStack(t *testing.T) {
+	scheduled := xerror.GoStack()
+	err := xerror.New("fmt")
+	cp := err.AppendStack(scheduled)
+	assert.Equal(t, len(err.Stack())+1+len(scheduled), len(cp.Stack()))
+
+	marker := -1
+	for i, frame := range cp.Stack() {
+		if frame == "--- continued from ---" {
+			marker = i
+			continue
+		}
+		assert.Regexp(t, frameRegexp, frame)
+	}
+	assert.Equal(t, len(err.Stack()), marker)
+	assert.Contains(t, cp.GoString(), "--- continued from ---")
+	assert.Contains(t, cp.GoldenString(""), "--- continued from ---")
+	assert.NotContains(t, err.Stack(), "--- continued from ---")
+}
+
+func TestSetFrameEntryCapture(t *testing.T) {
+	for _, f := range xerror.New("fmt").StackFrames() {
+		assert.Equal(t, uintptr(0), f.Entry)
+	}
+
+	xerror.SetFrameEntryCapture(true)
+	defer xerror.SetFrameEntryCapture(false)
+
+	err := xerror.New("fmt")
+	for _, f := range err.StackFrames() {
+		assert.NotEqual(t, uintptr(0), f.Entry)
+		assert.True(t, f.Entry <= f.PC)
+	}
+	assert.Regexp(t, frameRegexp, err.Stack()[0])
+	for _, f := range err.Materialize().StackFrames() {
+		assert.Equal(t, uintptr(0), f.Entry)
+	}
+}
+
+func newAtDepth(depth int) xerror.Error {
+	if depth == 0 {
+		return xerror.New("fmt")
+	}
+	return newAtDepth(depth - 1)
+}
+
+func TestSetMaxStackDepth(t *testing.T) {
+	assert.Len(t, newAtDepth(200).StackFrames(), xerror.DefaultMaxStackDepth)
+
+	xerror.SetMaxStackDepth(10)
+	defer xerror.SetMaxStackDepth(0)
+	assert.Len(t, newAtDepth(50).StackFrames(), 10)
+	assert.True(t, len(newAtDepth(0).StackFrames()) <= 10)
+
+	xerror.SetMaxStackDepth(300)
+	assert.Len(t, newAtDepth(400).StackFrames(), 300)
+	frames := newAtDepth(250).StackFrames()
+	assert.True(t, len(frames) > 250 && len(frames) < 300)
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.newAtDepth", frames[0].Function)
+
+	xerror.SetMaxStackDepth(0)
+	assert.Len(t, newAtDepth(200).StackFrames(), xerror.DefaultMaxStackDepth)
+}
+
+func newValidationError(field string) xerror.Error {
+	return xerror.NewWithSkip(2, "invalid field %v", field)
+}
+
+func TestNewWithSkip(t *testing.T) {
+	err := xerror.NewWithSkip(0, "fmt %v", "p1", "d1")
+	assert.Equal(t, "fmt p1", err.Error())
+	assert.Equal(t, []interface{}{"p1", "d1"}, err.Debug())
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror.NewWithSkip", err.StackFrames()[0].Function)
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestNewWithSkip", err.StackFrames()[1].Function)
+
+	err = xerror.NewWithSkip(1, "fmt")
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestNewWithSkip", err.StackFrames()[0].Function)
+
+	err = xerror.NewWithSkip(2, "fmt")
+	assert.Equal(t, "testing.tRunner", err.StackFrames()[0].Function)
+}
+
+func TestStack_StartsAtCallSite(t *testing.T) {
+	_, _, line, _ := runtime.Caller(0)
+	err := xerror.Wrap(errors.New("ew"), "fmt")
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestStack_StartsAtCallSite", err.StackFrames()[0].Function)
+	assert.True(t, strings.HasSuffix(err.StackFrames()[0].File, "xerror/stack_test.go"))
+	assert.Equal(t, line+1, err.StackFrames()[0].Line)
+
+	for _, err := range []xerror.Error{
+		xerror.New("fmt"),
+		xerror.WrapTimed(errors.New("ew"), time.Now(), "fmt"),
+		xerror.Combine(errors.New("a"), errors.New("b")),
+		xerror.RetriesExhausted(2, errors.New("ew")),
+	} {
+		assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestStack_StartsAtCallSite", err.StackFrames()[0].Function)
+		assert.False(t, err.StackContainsFile("xerror/error.go"))
+	}
+}
+
+func TestNewWithSkip_Helper(t *testing.T) {
+	err := newValidationError("userId")
+	assert.Equal(t, "invalid field userId", err.Error())
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestNewWithSkip_Helper", err.StackFrames()[0].Function)
+}
+
+func TestFormatStack(t *testing.T) {
+	err := xerror.Wrap(errors.New("ew"), "fmt")
+	lines := strings.Split(xerror.FormatStack(err, false), "\n")
+	assert.Equal(t, "fmt: ew", lines[0])
+	assert.Equal(t, len(err.Stack()), len(lines)-1)
+	for i, frame := range err.Stack() {
+		assert.Equal(t, "\t"+frame, lines[i+1])
+	}
+}
+
+func TestFormatStack_TrimInternal(t *testing.T) {
+	err := xerror.New("fmt")
+	lines := strings.Split(xerror.FormatStack(fmt.Errorf("wrapped: %w", err), true), "\n")
+	assert.Equal(t, "wrapped: fmt", lines[0])
+	assert.Contains(t, lines[1], "xerror_test.TestFormatStack_TrimInternal")
+	for _, line := range lines[1:] {
+		assert.NotContains(t, line, "(runtime.")
+		assert.NotContains(t, line, "(github.com/ibrt/go-xerror/xerror.")
+	}
+}
+
+func TestFormatStack_Native(t *testing.T) {
+	assert.Equal(t, "ew", xerror.FormatStack(errors.New("ew"), true))
+	assert.Equal(t, "", xerror.FormatStack(nil, true))
+}
+
+func TestStack_Resolution(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.Clone()
+	assert.Equal(t, "github.com/ibrt/go-xerror/xerror_test.TestStack_Resolution", cp.StackFrames()[0].Function)
+	assert.Equal(t, err.Stack(), cp.Stack())
+
+	m := err.Materialize()
+	assert.Equal(t, uintptr(0), m.StackFrames()[0].PC)
+	assert.NotEqual(t, uintptr(0), err.StackFrames()[0].PC)
+	assert.Len(t, xerror.NewNoStack("fmt").StackFrames(), 0)
+	assert.Len(t, xerror.NewNoStack("fmt").Stack(), 0)
+}
+
+func TestStack_ConcurrentResolution(t *testing.T) {
+	err := xerror.New("fmt")
+	cp := err.Clone()
+	done := make(chan []string)
+	for i := 0; i < 4; i++ {
+		go func() { done <- cp.Stack() }()
+	}
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, err.Stack(), <-done)
+	}
+}
+
+// BenchmarkNew compares deferred stack resolution, used by `New`, with eager resolution, emulated by resolving the
+// frames right after creating the error as `New` used to do.
+func BenchmarkNew(b *testing.B) {
+	b.Run("Deferred", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = xerror.New("fmt %v", i)
+		}
+	})
+	b.Run("Eager", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = xerror.New("fmt %v", i).StackFrames()
+		}
+	})
+}
+
+func BenchmarkStack(b *testing.B) {
+	err := xerror.New("fmt")
+	_ = err.StackFrames()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = err.Stack()
+	}
+}
+
+func TestSetStackCaptureEnabled(t *testing.T) {
+	xerror.SetStackCaptureEnabled(false)
+	defer xerror.SetStackCaptureEnabled(true)
+
+	for _, err := range []xerror.Error{
+		xerror.New("fmt"),
+		xerror.Wrap(errors.New("ew"), "fmt"),
+		xerror.NewWithSkip(1, "fmt"),
+		xerror.WrapWithParentStack(errors.New("ew"), xerror.GoStack(), "fmt"),
+	} {
+		assert.False(t, err.HasStack())
+		assert.False(t, err.StackContainsFile("stack_test.go"))
+		assert.NotPanics(t, func() {
+			_ = err.GoString()
+			_ = fmt.Sprintf("%+v", err)
+			_ = err.GoldenString("/")
+			_ = err.Materialize()
+			_ = err.EstimateJSONSize()
+			_ = xerror.FormatStack(err, true)
+			_ = xerror.GroupByTopFrame([]error{err})
+		})
+	}
+	assert.Empty(t, xerror.GoStack())
+	assert.Empty(t, xerror.New("fmt").StackFrames())
+
+	xerror.SetStackCaptureEnabled(true)
+	assert.True(t, xerror.New("fmt").HasStack())
+}
+
+func BenchmarkNew_StackCaptureDisabled(b *testing.B) {
+	xerror.SetStackCaptureEnabled(false)
+	defer xerror.SetStackCaptureEnabled(true)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = xerror.New("fmt %v", i)
+	}
+}
@@ -0,0 +1,44 @@
+package xerror
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var templateTokenRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// SetTemplatePlaceholder sets the string rendered by `RenderTemplate` in place of tokens that don't match any field.
+// Passing an empty string, which is the default, leaves unresolved tokens unchanged (e.g. "{key}").
+func SetTemplatePlaceholder(placeholder string) {
+	setConfig(func(c *config) {
+		c.templatePlaceholder = placeholder
+	})
+}
+
+// WithTemplate returns a copy of the `Error` with the given message template, in which `{key}` tokens reference fields
+// by name (e.g. "user {userID} in {region} not found"). The template is rendered by `RenderTemplate`. Wrapping
+// preserves the template unless the outer error sets its own.
+func (e *xerr) WithTemplate(template string) Error {
+	x := e.Clone().(*xerr)
+	x.template = template
+	return x
+}
+
+// RenderTemplate returns the message template with each `{key}` token replaced by the value of the corresponding
+// field. Unresolved tokens are rendered as set by `SetTemplatePlaceholder`. If no template is set, it returns the same
+// string as `Error`.
+func (e *xerr) RenderTemplate() string {
+	if e.template == "" {
+		return e.Error()
+	}
+	placeholder := getConfig().templatePlaceholder
+	return templateTokenRegexp.ReplaceAllStringFunc(e.template, func(token string) string {
+		if v, ok := e.fields[token[1:len(token)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		if placeholder != "" {
+			return placeholder
+		}
+		return token
+	})
+}
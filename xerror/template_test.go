@@ -0,0 +1,38 @@
+package xerror_test
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	err := xerror.New("user not found").
+		WithField("userID", 42).
+		WithField("region", "eu").
+		WithTemplate("user {userID} in {region} not found")
+	assert.Equal(t, "user 42 in eu not found", err.RenderTemplate())
+	assert.Equal(t, "user not found", err.Error())
+}
+
+func TestRenderTemplate_NoTemplate(t *testing.T) {
+	err := xerror.Wrap(xerror.New("fmt1"), "fmt2")
+	assert.Equal(t, "fmt2: fmt1", err.RenderTemplate())
+}
+
+func TestRenderTemplate_Unresolved(t *testing.T) {
+	err := xerror.New("fmt").WithField("a", "x").WithTemplate("{a} {b} {} {{a}}")
+	assert.Equal(t, "x {b} {} {x}", err.RenderTemplate())
+
+	xerror.SetTemplatePlaceholder("<missing>")
+	defer xerror.SetTemplatePlaceholder("")
+	assert.Equal(t, "x <missing> {} {x}", err.RenderTemplate())
+}
+
+func TestRenderTemplate_Wrap(t *testing.T) {
+	inner := xerror.New("fmt1").WithField("a", 1).WithTemplate("a={a}")
+	outer := xerror.Wrap(inner, "fmt2").WithField("a", 2)
+	assert.Equal(t, "a=1", inner.RenderTemplate())
+	assert.Equal(t, "a=2", outer.RenderTemplate())
+	assert.Equal(t, "b=2", outer.WithTemplate("b={a}").RenderTemplate())
+}
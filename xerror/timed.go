@@ -0,0 +1,33 @@
+package xerror
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FieldElapsed is the name of the field set by `WrapTimed`.
+const FieldElapsed = "elapsed"
+
+// Elapsed is the duration of a failed operation, attached to errors by `WrapTimed`. It is rendered in the
+// human-readable form of `time.Duration` (e.g. "1.2s"), also when serialized to JSON.
+type Elapsed time.Duration
+
+// String returns the human-readable form of the duration.
+func (d Elapsed) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalJSON implements the `json.Marshaler` interface.
+func (d Elapsed) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// WrapTimed is like `Wrap`, but also records the time elapsed since `start` (e.g. the time at which the failed
+// operation started) in the `FieldElapsed` field, measured with the clock set by `SetClock`.
+func WrapTimed(err error, start time.Time, format string, v ...interface{}) Error {
+	xerr := Wrap(err, format, v...).(*xerr)
+	xerr.fields = mergeFields(xerr.fields, map[string]interface{}{
+		FieldElapsed: Elapsed(now().Sub(start)),
+	})
+	return xerr
+}
@@ -0,0 +1,42 @@
+package xerror_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWrapTimed(t *testing.T) {
+	start := time.Now().Add(-1200 * time.Millisecond)
+	err := xerror.WrapTimed(io.EOF, start, "fmt %v", "p1")
+	assert.Equal(t, "fmt p1: EOF", err.Error())
+	assert.True(t, xerror.Is(err, "fmt %v"))
+
+	elapsed, ok := err.Fields()[xerror.FieldElapsed].(xerror.Elapsed)
+	assert.True(t, ok)
+	assert.True(t, time.Duration(elapsed) >= 1200*time.Millisecond)
+	assert.True(t, time.Duration(elapsed) < time.Minute)
+}
+
+func TestWrapTimed_Clock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	xerror.SetClock(func() time.Time { return start.Add(1200 * time.Millisecond) })
+	defer xerror.SetClock(nil)
+
+	err := xerror.WrapTimed(io.EOF, start, "fmt")
+	assert.Equal(t, xerror.Elapsed(1200*time.Millisecond), err.Fields()[xerror.FieldElapsed])
+}
+
+func TestElapsed_JSON(t *testing.T) {
+	assert.Equal(t, "1.2s", xerror.Elapsed(1200*time.Millisecond).String())
+	buf, err := json.Marshal(xerror.New("fmt").WithField(xerror.FieldElapsed, xerror.Elapsed(1200*time.Millisecond)))
+	assert.Nil(t, err)
+	m := struct {
+		Fields map[string]interface{} `json:"fields"`
+	}{}
+	assert.Nil(t, json.Unmarshal(buf, &m))
+	assert.Equal(t, "1.2s", m.Fields["elapsed"])
+}
@@ -0,0 +1,60 @@
+package xerror
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncationSuffix is appended to debug values truncated to the configured maximum length
+const truncationSuffix = "…"
+
+// SetDebugValueMaxLen limits the length of each debug object rendered by `MarshalJSON` (and therefore `GoString`),
+// `LogValue`, and the other renderings using `RenderedDebug`: strings longer than `n` characters, and other objects
+// whose JSON form is longer than `n` characters, are replaced by their first `n` characters followed by "…". It allows
+// to cap the size of logs at the granularity of single values, e.g. for a giant struct. A value of 0, the default,
+// means unlimited.
+func SetDebugValueMaxLen(n int) {
+	setConfig(func(c *config) {
+		c.debugValueMaxLen = n
+	})
+}
+
+// RenderedDebug returns the debug objects as every rendering of the error serializes them (`MarshalJSON`, `LogValue`,
+// and adapters such as package xzap): redacted by `SetDebugRedactor`, ordered by `SetDebugSortKey`, and limited by
+// `SetJSONMaxDebugDepth` and `SetDebugValueMaxLen`, in this order. `Debug` still returns the original objects.
+func (e *xerr) RenderedDebug() []interface{} {
+	return limitDebugLen(limitDebugDepth(sortDebug(redactDebug(e.dbg))))
+}
+
+// limitDebugLen returns the debug objects truncated to the configured maximum length
+func limitDebugLen(dbg []interface{}) []interface{} {
+	max := getConfig().debugValueMaxLen
+	if max <= 0 {
+		return dbg
+	}
+	limited := make([]interface{}, 0, len(dbg))
+	for _, d := range dbg {
+		limited = append(limited, limitLen(d, max))
+	}
+	return limited
+}
+
+// limitLen returns the given debug object, or its truncated rendered form if longer than `max` characters
+func limitLen(v interface{}, max int) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		buf, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("!ERROR(%v)", err)
+		}
+		if utf8.RuneCount(buf) <= max {
+			return v
+		}
+		s = string(buf)
+	}
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max]) + truncationSuffix
+}
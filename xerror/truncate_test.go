@@ -0,0 +1,57 @@
+package xerror_test
+
+import (
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSetDebugValueMaxLen_Disabled(t *testing.T) {
+	long := strings.Repeat("a", 100)
+	assert.Equal(t, []interface{}{long}, debugJSON(t, xerror.New("fmt", long)))
+}
+
+func TestSetDebugValueMaxLen(t *testing.T) {
+	xerror.SetDebugValueMaxLen(5)
+	defer xerror.SetDebugValueMaxLen(0)
+
+	err := xerror.New("fmt", "abc", "abcdefgh", "ééééééé", 12345, 1234567, []int{1, 2, 3}, map[string]int{"k": 1})
+	assert.Equal(t, []interface{}{
+		"abc",
+		"abcde…",
+		"ééééé…",
+		12345.0,
+		"12345…",
+		"[1,2,…",
+		"{\"k\":…",
+	}, debugJSON(t, err))
+	assert.Equal(t, "abcdefgh", err.Debug()[1])
+}
+
+func TestSetDebugValueMaxLen_Unmarshalable(t *testing.T) {
+	xerror.SetDebugValueMaxLen(5)
+	defer xerror.SetDebugValueMaxLen(0)
+
+	assert.Equal(t, []interface{}{"!ERROR(json: unsupported type: chan int)"}, debugJSON(t, xerror.New("fmt", make(chan int))))
+}
+
+func TestRenderedDebug(t *testing.T) {
+	xerror.SetDebugValueMaxLen(5)
+	defer xerror.SetDebugValueMaxLen(0)
+	xerror.SetDebugSortKey(func(v interface{}) string { return fmt.Sprint(v) })
+	defer xerror.SetDebugSortKey(nil)
+	xerror.SetDebugRedactor(func(v interface{}) interface{} {
+		if v == "password" {
+			return "***"
+		}
+		return v
+	})
+	defer xerror.SetDebugRedactor(nil)
+
+	err := xerror.New("fmt", "zzzzzzzz", "password", "abc")
+	assert.Equal(t, []interface{}{"***", "abc", "zzzzz…"}, err.RenderedDebug())
+	assert.Equal(t, debugJSON(t, err), err.RenderedDebug())
+	assert.Equal(t, "zzzzzzzz", err.Debug()[0])
+}
@@ -0,0 +1,42 @@
+package xerror
+
+import (
+	"strings"
+)
+
+// fmtArtifacts are the markers inserted by package fmt in messages rendered with wrong verbs or parameters
+var fmtArtifacts = []string{"%!", "(MISSING)", "(EXTRA"}
+
+// IsWellFormed returns false if the rendered message of any layer contains the markers inserted by package fmt for
+// wrong verbs or missing parameters (e.g. "%!v(MISSING)"), true otherwise. It is meant as a lightweight check in tests
+// that errors are created with the parameters their formats expect.
+func (e *xerr) IsWellFormed() bool {
+	for _, layer := range e.renderedLayers() {
+		if !isWellFormed(layer) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWellFormed is like the `IsWellFormed` method, but accepts any `error`, checking the error string of Go errors. It
+// returns true if `err` is nil.
+func IsWellFormed(err error) bool {
+	if err == nil {
+		return true
+	}
+	if xerr, ok := err.(*xerr); ok {
+		return xerr.IsWellFormed()
+	}
+	return isWellFormed(err.Error())
+}
+
+// isWellFormed returns true if the given message contains no fmt artifacts
+func isWellFormed(msg string) bool {
+	for _, a := range fmtArtifacts {
+		if strings.Contains(msg, a) {
+			return false
+		}
+	}
+	return true
+}
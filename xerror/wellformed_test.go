@@ -0,0 +1,25 @@
+package xerror_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsWellFormed(t *testing.T) {
+	assert.True(t, xerror.New("fmt %v", "p1").IsWellFormed())
+	assert.True(t, xerror.New("100%% done").IsWellFormed())
+	assert.False(t, xerror.New("fmt %v").IsWellFormed())
+	assert.False(t, xerror.New("fmt %d", "p1").IsWellFormed())
+	assert.False(t, xerror.Wrap(xerror.New("fmt %v %v", "p1"), "fmt2").IsWellFormed())
+	assert.False(t, xerror.WrapLazy(xerror.New("fmt"), func() string { return "fmt %!v(MISSING)" }).IsWellFormed())
+}
+
+func TestIsWellFormed_TopLevel(t *testing.T) {
+	assert.True(t, xerror.IsWellFormed(xerror.New("fmt")))
+	assert.False(t, xerror.IsWellFormed(xerror.New("fmt %v")))
+	assert.True(t, xerror.IsWellFormed(errors.New("ew")))
+	assert.False(t, xerror.IsWellFormed(errors.New("fmt p1%!(EXTRA string=p2)")))
+	assert.True(t, xerror.IsWellFormed(nil))
+}
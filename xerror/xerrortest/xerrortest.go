@@ -0,0 +1,116 @@
+/*
+Package xerrortest provides helpers to compare errors in tests. It is kept separate from package xerror so that only
+tests depend on the comparison libraries.
+*/
+package xerrortest
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/ibrt/go-xerror/xerror"
+	"reflect"
+	"sort"
+)
+
+// CmpOption returns a go-cmp option comparing `xerror.Error` values by message and message formats, ignoring stacks,
+// debug objects and instance IDs, e.g. `cmp.Diff(want, got, xerrortest.CmpOption())`.
+func CmpOption() cmp.Option {
+	return cmp.Comparer(func(a, b xerror.Error) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Error() == b.Error() && len(xerror.FormatsDiff(a, b)) == 0
+	})
+}
+
+// attributes holds the semantic attributes of an error compared by Diff
+type attributes struct {
+	code     string
+	kind     xerror.Kind
+	severity xerror.Severity
+	fields   map[string]interface{}
+}
+
+// Diff returns a human-readable description of the differences between the semantic attributes of the given errors:
+// message, message formats, code, kind, severity, and fields. Stacks, debug objects and instance IDs are ignored. An
+// empty string means that the errors are equal. Go errors are compared by error string only, and have no code, no
+// kind, the default severity, and no fields.
+func Diff(want, got error) string {
+	if want == nil || got == nil {
+		if want == nil && got == nil {
+			return ""
+		}
+		return fmt.Sprintf("error: want %v, got %v\n", describe(want), describe(got))
+	}
+	buf := &bytes.Buffer{}
+	if want.Error() != got.Error() {
+		fmt.Fprintf(buf, "message: want %q, got %q\n", want.Error(), got.Error())
+	}
+	for _, f := range xerror.FormatsDiff(want, got) {
+		if xerror.Contains(want, f) {
+			fmt.Fprintf(buf, "format: want %q, got none\n", f)
+		} else {
+			fmt.Fprintf(buf, "format: want none, got %q\n", f)
+		}
+	}
+	wa, ga := attributesOf(want), attributesOf(got)
+	if wa.code != ga.code {
+		fmt.Fprintf(buf, "code: want %q, got %q\n", wa.code, ga.code)
+	}
+	if wa.kind != ga.kind {
+		fmt.Fprintf(buf, "kind: want %q, got %q\n", wa.kind, ga.kind)
+	}
+	if wa.severity != ga.severity {
+		fmt.Fprintf(buf, "severity: want %v, got %v\n", wa.severity, ga.severity)
+	}
+	for _, k := range fieldKeys(wa.fields, ga.fields) {
+		wv, wok := wa.fields[k]
+		gv, gok := ga.fields[k]
+		switch {
+		case !gok:
+			fmt.Fprintf(buf, "field %q: want %#v, got none\n", k, wv)
+		case !wok:
+			fmt.Fprintf(buf, "field %q: want none, got %#v\n", k, gv)
+		case !reflect.DeepEqual(wv, gv):
+			fmt.Fprintf(buf, "field %q: want %#v, got %#v\n", k, wv, gv)
+		}
+	}
+	return buf.String()
+}
+
+// describe returns a short description of the given error for Diff
+func describe(err error) string {
+	if err == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%q", err.Error())
+}
+
+// attributesOf returns the semantic attributes of the given error
+func attributesOf(err error) attributes {
+	if x, ok := err.(xerror.Error); ok {
+		return attributes{
+			code:     x.Code(),
+			kind:     x.Kind(),
+			severity: x.Severity(),
+			fields:   x.Fields(),
+		}
+	}
+	return attributes{severity: xerror.DefaultSeverity}
+}
+
+// fieldKeys returns the sorted union of the keys of the given fields
+func fieldKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,74 @@
+package xerrortest_test
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/ibrt/go-xerror/xerror/xerrortest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCmpOption_Equal(t *testing.T) {
+	want := xerror.Wrap(xerror.New("fmt %v", "p1", "d1"), "fmt2")
+	got := xerror.Wrap(xerror.New("fmt %v", "p1", "d2"), "fmt2")
+	assert.True(t, cmp.Equal(want, got, xerrortest.CmpOption()))
+	assert.Equal(t, "", cmp.Diff(want, got, xerrortest.CmpOption()))
+}
+
+func TestCmpOption_DifferentMessage(t *testing.T) {
+	want := xerror.New("fmt %v", "p1")
+	got := xerror.New("fmt %v", "p2")
+	assert.False(t, cmp.Equal(want, got, xerrortest.CmpOption()))
+	assert.NotEqual(t, "", cmp.Diff(want, got, xerrortest.CmpOption()))
+}
+
+func TestCmpOption_DifferentFormats(t *testing.T) {
+	want := xerror.Wrap(errors.New("ew"), "fmt p1")
+	got := xerror.Wrap(errors.New("ew"), "fmt %v", "p1")
+	assert.False(t, cmp.Equal(want, got, xerrortest.CmpOption()))
+}
+
+func TestDiff_Equal(t *testing.T) {
+	want := xerror.Wrap(xerror.New("fmt %v", "p1", "d1"), "fmt2").WithCode("CODE").WithField("k", 1)
+	got := xerror.Wrap(xerror.New("fmt %v", "p1", "d2"), "fmt2").WithCode("CODE").WithField("k", 1)
+	assert.Equal(t, "", xerrortest.Diff(want, got))
+	assert.Equal(t, "", xerrortest.Diff(nil, nil))
+	assert.Equal(t, "", xerrortest.Diff(errors.New("ew"), errors.New("ew")))
+}
+
+func TestDiff_Attributes(t *testing.T) {
+	want := xerror.New("fmt %v", "p1").
+		WithCode("CODE1").
+		WithSeverity(xerror.SeverityWarning).
+		WithFields(map[string]interface{}{"k1": 1, "k2": "v2"})
+	got := xerror.New("fmt2").
+		WithCode("CODE2").
+		WithFields(map[string]interface{}{"k1": 2, "k3": true})
+	assert.Equal(t, ""+
+		"message: want \"fmt p1\", got \"fmt2\"\n"+
+		"format: want \"fmt %v\", got none\n"+
+		"format: want none, got \"fmt2\"\n"+
+		"code: want \"CODE1\", got \"CODE2\"\n"+
+		"severity: want warning, got error\n"+
+		"field \"k1\": want 1, got 2\n"+
+		"field \"k2\": want \"v2\", got none\n"+
+		"field \"k3\": want none, got true\n",
+		xerrortest.Diff(want, got))
+}
+
+func TestDiff_Kind(t *testing.T) {
+	want := xerror.WrapKind(errors.New("ew"), "KindA")
+	got := xerror.WrapKind(errors.New("ew"), "KindB")
+	assert.Equal(t, "kind: want \"KindA\", got \"KindB\"\n", xerrortest.Diff(want, got))
+}
+
+func TestDiff_NativeErr(t *testing.T) {
+	assert.Equal(t, "", xerrortest.Diff(errors.New("ew"), xerror.New("ew")))
+	assert.Equal(t, "code: want \"\", got \"CODE\"\n", xerrortest.Diff(errors.New("ew"), xerror.New("ew").WithCode("CODE")))
+	assert.Equal(t, "message: want \"ew\", got \"ew2\"\n"+
+		"format: want \"ew\", got none\n"+
+		"format: want none, got \"ew2\"\n", xerrortest.Diff(errors.New("ew"), errors.New("ew2")))
+	assert.Equal(t, "error: want nil, got \"ew\"\n", xerrortest.Diff(nil, errors.New("ew")))
+	assert.Equal(t, "error: want \"ew\", got nil\n", xerrortest.Diff(xerror.New("ew"), nil))
+}
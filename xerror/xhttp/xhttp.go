@@ -0,0 +1,109 @@
+/*
+Package xhttp provides helpers to use package xerror in HTTP clients and servers.
+*/
+package xhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// ErrorUnexpectedStatus is the message format of errors created by FromHTTPResponse.
+	ErrorUnexpectedStatus = "unexpected HTTP status"
+
+	// ErrorHandlerPanic is the message format of errors created by Recover.
+	ErrorHandlerPanic = "panic in HTTP handler"
+
+	// MaxBodyLen is the maximum number of body bytes stored in errors created by FromHTTPResponse.
+	MaxBodyLen = 4096
+)
+
+// readCloser combines a reader with the closer of the original body it replays
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// FromHTTPResponse returns an error describing the given (usually failed) response. The error has the response's
+// HTTP status, a code derived from it (e.g. "NOT_FOUND" for 404, or "HTTP_<status>" for unknown statuses), and the
+// "status" and "body" fields.
+//
+// At most MaxBodyLen bytes of the body are read and stored. The body is not consumed: `resp.Body` is replaced with a
+// reader that replays the bytes read followed by the rest of the original body, so the caller can still read it in
+// full and remains responsible for closing it. Errors reading the body are ignored, storing what could be read.
+func FromHTTPResponse(resp *http.Response) xerror.Error {
+	var body []byte
+	if resp.Body != nil {
+		body, _ = io.ReadAll(io.LimitReader(resp.Body, MaxBodyLen))
+		resp.Body = &readCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), resp.Body),
+			Closer: resp.Body,
+		}
+	}
+	fields := map[string]interface{}{
+		"status": resp.Status,
+		"body":   string(body),
+	}
+	return xerror.NewWithFields(ErrorUnexpectedStatus, fields).
+		WithCode(StatusCode(resp.StatusCode)).
+		WithHTTPStatus(resp.StatusCode)
+}
+
+// StatusCode returns the error code derived from the given HTTP status, e.g. "NOT_FOUND" for 404, or "HTTP_<status>"
+// if the status is unknown.
+func StatusCode(status int) string {
+	if text := http.StatusText(status); text != "" {
+		return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+	}
+	return fmt.Sprintf("HTTP_%v", status)
+}
+
+// recoverJSON is the body of the responses written by Recover
+type recoverJSON struct {
+	ID      string   `json:"id"`
+	Message string   `json:"message"`
+	Stack   []string `json:"stack,omitempty"`
+}
+
+// Recover returns a handler that calls `next`, recovering from any panic it raises (except `http.ErrAbortHandler`,
+// which is re-raised to preserve the semantics of package http). The recovered value is converted to an error with
+// `xerror.FromPanic`, so that it carries the stack of the panic, and wrapped with `xerror.WrapLog`, so that it is
+// passed to the logger registered with `xerror.SetLogger`, if any. The handler then responds with status 500 and a JSON
+// body that only contains the error's instance ID and a generic message (or the user message of the recovered error,
+// if it has one), to correlate the response with the logged error without exposing its internals. Outside of production
+// mode (see `xerror.SetProductionMode`) the body also contains the stack, to ease debugging.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			err := xerror.WrapLog(xerror.FromPanic(rec), ErrorHandlerPanic)
+			msg := err.UserMessage()
+			if msg == "" {
+				msg = http.StatusText(http.StatusInternalServerError)
+			}
+			body := &recoverJSON{
+				ID:      err.InstanceID(),
+				Message: msg,
+			}
+			if !xerror.ProductionMode() {
+				body.Stack = err.Stack()
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(body)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,125 @@
+package xhttp_test
+
+import (
+	"encoding/json"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/ibrt/go-xerror/xerror/xhttp"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromHTTPResponse(t *testing.T) {
+	resp := &http.Response{
+		Status:     "404 Not Found",
+		StatusCode: http.StatusNotFound,
+		Body:       io.NopCloser(strings.NewReader("no such user")),
+	}
+	err := xhttp.FromHTTPResponse(resp)
+	assert.True(t, xerror.Is(err, xhttp.ErrorUnexpectedStatus))
+	assert.Equal(t, "NOT_FOUND", err.Code())
+	status, ok := err.HTTPStatus()
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, map[string]interface{}{"status": "404 Not Found", "body": "no such user"}, err.Fields())
+
+	body, err2 := io.ReadAll(resp.Body)
+	assert.Nil(t, err2)
+	assert.Equal(t, "no such user", string(body))
+	assert.Nil(t, resp.Body.Close())
+}
+
+func TestFromHTTPResponse_LongBody(t *testing.T) {
+	long := strings.Repeat("x", xhttp.MaxBodyLen+10)
+	resp := &http.Response{
+		Status:     "500 Internal Server Error",
+		StatusCode: http.StatusInternalServerError,
+		Body:       io.NopCloser(strings.NewReader(long)),
+	}
+	err := xhttp.FromHTTPResponse(resp)
+	assert.Equal(t, long[:xhttp.MaxBodyLen], err.Fields()["body"])
+
+	body, err2 := io.ReadAll(resp.Body)
+	assert.Nil(t, err2)
+	assert.Equal(t, long, string(body))
+}
+
+func TestFromHTTPResponse_NoBody(t *testing.T) {
+	err := xhttp.FromHTTPResponse(&http.Response{Status: "502 Bad Gateway", StatusCode: http.StatusBadGateway})
+	assert.Equal(t, "BAD_GATEWAY", err.Code())
+	assert.Equal(t, "", err.Fields()["body"])
+}
+
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, "NOT_FOUND", xhttp.StatusCode(http.StatusNotFound))
+	assert.Equal(t, "IM_A_TEAPOT", xhttp.StatusCode(http.StatusTeapot))
+	assert.Equal(t, "NON_AUTHORITATIVE_INFORMATION", xhttp.StatusCode(http.StatusNonAuthoritativeInfo))
+	assert.Equal(t, "HTTP_599", xhttp.StatusCode(599))
+}
+
+func TestRecover(t *testing.T) {
+	var logged xerror.Error
+	xerror.SetLogger(func(err xerror.Error) { logged = err })
+	defer xerror.SetLogger(nil)
+
+	h := xhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	body := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, map[string]interface{}{"id": logged.InstanceID(), "message": "Internal Server Error"}, body)
+
+	assert.Equal(t, "panic in HTTP handler: panic: boom", logged.Error())
+	assert.True(t, logged.Contains(xerror.ErrorPanic))
+	assert.True(t, logged.StackContainsFile("xhttp_test.go"))
+}
+
+func TestRecover_UserMessage(t *testing.T) {
+	h := xhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(xerror.New("fmt").WithUserMessage("Try again later."))
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"Try again later."`)
+}
+
+func TestRecover_Stack(t *testing.T) {
+	xerror.SetProductionMode(false)
+	defer xerror.SetProductionMode(true)
+
+	h := xhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.NotEmpty(t, body["stack"])
+}
+
+func TestRecover_NoPanic(t *testing.T) {
+	h := xhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
+
+func TestRecover_ErrAbortHandler(t *testing.T) {
+	h := xhttp.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,128 @@
+/*
+Package xotel converts errors to OpenTelemetry data. It is kept separate from package xerror so that only programs
+using it depend on OpenTelemetry.
+*/
+package xotel
+
+import (
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+	"sort"
+	"strings"
+)
+
+// ErrorInvalidSpanContext is the message format of the errors returned by ToSpanContext.
+const ErrorInvalidSpanContext = "invalid span context"
+
+// Attribute keys used in log records, following the OpenTelemetry semantic conventions where they exist.
+const (
+	AttributeMessage = "exception.message"
+	AttributeStack   = "exception.stacktrace"
+	AttributeCode    = "error.code"
+)
+
+// ToLogRecord returns an OpenTelemetry log record describing the given error. The body is the error message, the
+// severity is mapped from the error's `Severity` (Go errors use `xerror.DefaultSeverity`), and the attributes contain
+// the message, the code, the stack and the fields of the error. A nil error returns an empty record.
+func ToLogRecord(err error) log.Record {
+	var r log.Record
+	if err == nil {
+		return r
+	}
+	r.SetBody(log.StringValue(err.Error()))
+	r.AddAttributes(log.String(AttributeMessage, err.Error()))
+
+	severity := xerror.DefaultSeverity
+	if xerr, ok := err.(xerror.Error); ok {
+		severity = xerr.Severity()
+		if code := xerr.Code(); code != "" {
+			r.AddAttributes(log.String(AttributeCode, code))
+		}
+		if stack := xerr.Stack(); len(stack) > 0 {
+			r.AddAttributes(log.String(AttributeStack, strings.Join(stack, "\n")))
+		}
+		fields := xerr.Fields()
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r.AddAttributes(log.KeyValue{Key: k, Value: toValue(fields[k])})
+		}
+	}
+	r.SetSeverity(ToSeverity(severity))
+	r.SetSeverityText(severity.String())
+	return r
+}
+
+// ToSeverity maps the given severity to the OpenTelemetry severity number of the same level (e.g. `SeverityWarning`
+// to `log.SeverityWarn`, i.e. WARN, 13). Unknown severities map to `log.SeverityUndefined`.
+func ToSeverity(s xerror.Severity) log.Severity {
+	switch s {
+	case xerror.SeverityDebug:
+		return log.SeverityDebug
+	case xerror.SeverityInfo:
+		return log.SeverityInfo
+	case xerror.SeverityWarning:
+		return log.SeverityWarn
+	case xerror.SeverityError:
+		return log.SeverityError
+	case xerror.SeverityFatal:
+		return log.SeverityFatal
+	default:
+		return log.SeverityUndefined
+	}
+}
+
+// toValue converts a field value to a log value, falling back to its default string representation
+func toValue(v interface{}) log.Value {
+	switch v := v.(type) {
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int64:
+		return log.Int64Value(v)
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	default:
+		return log.StringValue(fmt.Sprint(v))
+	}
+}
+
+// FromSpanContext converts the given OpenTelemetry span context, e.g. from `trace.SpanContextFromContext`, to be
+// attached to errors with `WithSpanContext`. It returns false if the span context is not valid.
+func FromSpanContext(sc trace.SpanContext) (xerror.SpanContext, bool) {
+	if !sc.IsValid() {
+		return xerror.SpanContext{}, false
+	}
+	return xerror.SpanContext{
+		TraceID: sc.TraceID().String(),
+		SpanID:  sc.SpanID().String(),
+	}, true
+}
+
+// ToSpanContext converts the given span context to a remote OpenTelemetry span context, e.g. to link a span to the one
+// in which an error occurred. It fails if the IDs are not valid hex-encoded W3C IDs.
+func ToSpanContext(sc xerror.SpanContext) (trace.SpanContext, error) {
+	traceID, err := trace.TraceIDFromHex(sc.TraceID)
+	if err != nil {
+		return trace.SpanContext{}, xerror.Wrap(err, ErrorInvalidSpanContext, sc)
+	}
+	spanID, err := trace.SpanIDFromHex(sc.SpanID)
+	if err != nil {
+		return trace.SpanContext{}, xerror.Wrap(err, ErrorInvalidSpanContext, sc)
+	}
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Remote:  true,
+	}), nil
+}
@@ -0,0 +1,80 @@
+package xotel_test
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/ibrt/go-xerror/xerror/xotel"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+)
+
+func attributes(r log.Record) map[string]log.Value {
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	return attrs
+}
+
+func TestToLogRecord_Error(t *testing.T) {
+	err := xerror.NewWithFields("fmt", map[string]interface{}{"userID": 42, "name": "n"}).
+		WithCode("CODE").
+		WithSeverity(xerror.SeverityWarning)
+	r := xotel.ToLogRecord(err)
+	assert.Equal(t, "fmt", r.Body().AsString())
+	assert.Equal(t, log.SeverityWarn, r.Severity())
+	assert.Equal(t, "warning", r.SeverityText())
+
+	attrs := attributes(r)
+	assert.Equal(t, "fmt", attrs[xotel.AttributeMessage].AsString())
+	assert.Equal(t, "CODE", attrs[xotel.AttributeCode].AsString())
+	assert.NotEqual(t, "", attrs[xotel.AttributeStack].AsString())
+	assert.Equal(t, int64(42), attrs["userID"].AsInt64())
+	assert.Equal(t, "n", attrs["name"].AsString())
+}
+
+func TestToLogRecord_NativeErr(t *testing.T) {
+	r := xotel.ToLogRecord(errors.New("ew"))
+	assert.Equal(t, "ew", r.Body().AsString())
+	assert.Equal(t, log.SeverityError, r.Severity())
+	assert.Equal(t, 1, r.AttributesLen())
+}
+
+func TestToLogRecord_Nil(t *testing.T) {
+	assert.Equal(t, log.Record{}, xotel.ToLogRecord(nil))
+}
+
+func TestToSeverity(t *testing.T) {
+	assert.Equal(t, log.Severity(5), xotel.ToSeverity(xerror.SeverityDebug))
+	assert.Equal(t, log.Severity(9), xotel.ToSeverity(xerror.SeverityInfo))
+	assert.Equal(t, log.Severity(13), xotel.ToSeverity(xerror.SeverityWarning))
+	assert.Equal(t, log.Severity(17), xotel.ToSeverity(xerror.SeverityError))
+	assert.Equal(t, log.Severity(21), xotel.ToSeverity(xerror.SeverityFatal))
+	assert.Equal(t, log.SeverityUndefined, xotel.ToSeverity(xerror.Severity(0)))
+}
+
+func TestSpanContext_RoundTrip(t *testing.T) {
+	sc := xerror.SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}
+	otelSC, err := xotel.ToSpanContext(sc)
+	assert.Nil(t, err)
+	assert.True(t, otelSC.IsValid())
+	assert.True(t, otelSC.IsRemote())
+	back, ok := xotel.FromSpanContext(otelSC)
+	assert.True(t, ok)
+	assert.Equal(t, sc, back)
+}
+
+func TestFromSpanContext_Invalid(t *testing.T) {
+	_, ok := xotel.FromSpanContext(trace.SpanContext{})
+	assert.False(t, ok)
+}
+
+func TestToSpanContext_Invalid(t *testing.T) {
+	_, err := xotel.ToSpanContext(xerror.SpanContext{TraceID: "nope", SpanID: "00f067aa0ba902b7"})
+	assert.True(t, xerror.Is(err, xotel.ErrorInvalidSpanContext))
+	_, err = xotel.ToSpanContext(xerror.SpanContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "nope"})
+	assert.True(t, xerror.Is(err, xotel.ErrorInvalidSpanContext))
+}
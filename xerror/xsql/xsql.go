@@ -0,0 +1,110 @@
+/*
+Package xsql provides helpers to use package xerror with database/sql drivers. It detects driver errors through
+well-known methods and fields rather than importing the drivers.
+*/
+package xsql
+
+import (
+	"errors"
+	"github.com/ibrt/go-xerror/xerror"
+	"reflect"
+)
+
+// FieldRetryable is the name of the field set by Wrap to tell whether the driver error is transient.
+const FieldRetryable = "retryable"
+
+// retryableStates are the SQLSTATEs of transient failures that are expected to succeed when retried
+var retryableStates = map[string]bool{
+	"40001": true, // serialization_failure (also MySQL ER_LOCK_DEADLOCK)
+	"40P01": true, // deadlock_detected (PostgreSQL)
+}
+
+// retryableNumbers are the MySQL error numbers of transient failures
+var retryableNumbers = map[uint64]bool{
+	1205: true, // ER_LOCK_WAIT_TIMEOUT
+	1213: true, // ER_LOCK_DEADLOCK
+}
+
+// Wrap is like `xerror.Wrap`, but if a driver error with a SQLSTATE is found in the chain of `err`, the returned
+// error is tagged with the SQLSTATE as code, and flagged as retryable (see `xerror.IsRetryable`) if the failure is
+// transient (serialization failures, deadlocks and lock wait timeouts). The FieldRetryable field is also set to the
+// same value.
+func Wrap(err error, format string, v ...interface{}) xerror.Error {
+	xerr := xerror.Wrap(err, format, v...)
+	state, ok := SQLState(err)
+	if !ok {
+		return xerr
+	}
+	retryable := isRetryable(err, state)
+	return xerr.WithCode(state).WithField(FieldRetryable, retryable).WithRetryable(retryable)
+}
+
+// SQLState returns the SQLSTATE of the first driver error found in the chain of `err`, and whether one was found. It
+// recognizes errors with a `SQLState() string` method (e.g. pgx), a 5-character `Code` string field (e.g. lib/pq), or
+// a `SQLState [5]byte` field (e.g. go-sql-driver/mysql).
+func SQLState(err error) (string, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if s, ok := err.(interface {
+			SQLState() string
+		}); ok {
+			return s.SQLState(), true
+		}
+		if s, ok := sqlStateField(err); ok {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// isRetryable returns true if the given SQLSTATE or the MySQL error number in the chain of `err` denotes a transient
+// failure
+func isRetryable(err error, state string) bool {
+	if retryableStates[state] {
+		return true
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		if f, ok := exportedField(err, "Number"); ok {
+			switch f.Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return retryableNumbers[f.Uint()]
+			}
+		}
+	}
+	return false
+}
+
+// sqlStateField extracts the SQLSTATE from the `Code` or `SQLState` field of the given error, if any
+func sqlStateField(err error) (string, bool) {
+	if f, ok := exportedField(err, "Code"); ok && f.Kind() == reflect.String && len(f.String()) == 5 {
+		return f.String(), true
+	}
+	if f, ok := exportedField(err, "SQLState"); ok && f.Kind() == reflect.Array && f.Len() == 5 &&
+		f.Type().Elem().Kind() == reflect.Uint8 {
+		buf := make([]byte, 5)
+		for i := range buf {
+			buf[i] = byte(f.Index(i).Uint())
+		}
+		if buf[0] != 0 {
+			return string(buf), true
+		}
+	}
+	return "", false
+}
+
+// exportedField returns the exported field with the given name of the struct (or pointer to struct) `err`, if any
+func exportedField(err error, name string) (reflect.Value, bool) {
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	if sf, ok := v.Type().FieldByName(name); !ok || sf.PkgPath != "" {
+		return reflect.Value{}, false
+	}
+	return v.FieldByName(name), true
+}
@@ -0,0 +1,96 @@
+package xsql_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/ibrt/go-xerror/xerror/xsql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// pgxError mimics pgconn.PgError
+type pgxError struct {
+	code string
+}
+
+func (e *pgxError) Error() string    { return "pgx error " + e.code }
+func (e *pgxError) SQLState() string { return e.code }
+
+// pqError mimics pq.Error
+type pqError struct {
+	Code    pqErrorCode
+	Message string
+}
+
+type pqErrorCode string
+
+func (e *pqError) Error() string { return "pq: " + e.Message }
+
+// mysqlError mimics mysql.MySQLError
+type mysqlError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *mysqlError) Error() string { return fmt.Sprintf("Error %d: %s", e.Number, e.Message) }
+
+func TestWrap_NativeErr(t *testing.T) {
+	err := xsql.Wrap(errors.New("ew"), "query failed")
+	assert.Equal(t, "query failed: ew", err.Error())
+	assert.Equal(t, "", err.Code())
+	assert.Equal(t, map[string]interface{}{}, err.Fields())
+}
+
+func TestWrap_SQLStateMethod(t *testing.T) {
+	err := xsql.Wrap(&pgxError{code: "40P01"}, "query %v failed", "q1")
+	assert.Equal(t, "query q1 failed: pgx error 40P01", err.Error())
+	assert.Equal(t, "40P01", err.Code())
+	assert.Equal(t, true, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, true, xerror.IsRetryable(err))
+
+	err = xsql.Wrap(&pgxError{code: "23505"}, "query failed")
+	assert.Equal(t, "23505", err.Code())
+	assert.Equal(t, false, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, false, xerror.IsRetryable(err))
+}
+
+func TestWrap_CodeField(t *testing.T) {
+	err := xsql.Wrap(&pqError{Code: "40001", Message: "could not serialize"}, "query failed")
+	assert.Equal(t, "40001", err.Code())
+	assert.Equal(t, true, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, true, xerror.IsRetryable(err))
+}
+
+func TestWrap_SQLStateField(t *testing.T) {
+	err := xsql.Wrap(&mysqlError{Number: 1205, SQLState: [5]byte{'H', 'Y', '0', '0', '0'}}, "query failed")
+	assert.Equal(t, "HY000", err.Code())
+	assert.Equal(t, true, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, true, xerror.IsRetryable(err))
+
+	err = xsql.Wrap(&mysqlError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}}, "query failed")
+	assert.Equal(t, "23000", err.Code())
+	assert.Equal(t, false, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, false, xerror.IsRetryable(err))
+}
+
+func TestWrap_WrappedDriverErr(t *testing.T) {
+	err := xsql.Wrap(xerror.Wrap(&pgxError{code: "40001"}, "inner"), "query failed")
+	assert.Equal(t, "40001", err.Code())
+	assert.Equal(t, true, err.Fields()[xsql.FieldRetryable])
+	assert.Equal(t, true, xerror.IsRetryable(err))
+}
+
+func TestSQLState(t *testing.T) {
+	state, ok := xsql.SQLState(fmt.Errorf("wrapped: %w", &pqError{Code: "42P01"}))
+	assert.True(t, ok)
+	assert.Equal(t, "42P01", state)
+
+	_, ok = xsql.SQLState(errors.New("ew"))
+	assert.False(t, ok)
+	_, ok = xsql.SQLState(nil)
+	assert.False(t, ok)
+	_, ok = xsql.SQLState(&mysqlError{Number: 2000})
+	assert.False(t, ok)
+}
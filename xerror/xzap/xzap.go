@@ -0,0 +1,90 @@
+/*
+Package xzap adapts errors to go.uber.org/zap. It is kept separate from package xerror so that only programs using it
+depend on zap.
+*/
+package xzap
+
+import (
+	"github.com/ibrt/go-xerror/xerror"
+	"go.uber.org/zap/zapcore"
+	"sort"
+)
+
+// Keys of the fields describing errors in zap log entries.
+const (
+	KeyMessage = "message"
+	KeyCode    = "code"
+	KeyDebug   = "debug"
+	KeyStack   = "stack"
+	KeyFields  = "fields"
+)
+
+// Object returns a `zapcore.ObjectMarshaler` describing the given error, e.g. for `zap.Object("error", Object(err))`.
+// It writes the message and, for an `xerror.Error`, the code (if set), the debug objects (as returned by
+// `RenderedDebug`), the stack, and the fields nested in a `KeyFields` object so that they can't collide with the other
+// keys, each omitted if empty. Plain Go errors only get the message, and a nil error writes no fields.
+func Object(err error) zapcore.ObjectMarshaler {
+	return errorMarshaler{err: err}
+}
+
+// errorMarshaler implements `zapcore.ObjectMarshaler` for an error
+type errorMarshaler struct {
+	err error
+}
+
+// MarshalLogObject implements the `zapcore.ObjectMarshaler` interface.
+func (m errorMarshaler) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if m.err == nil {
+		return nil
+	}
+	enc.AddString(KeyMessage, m.err.Error())
+
+	xerr, ok := m.err.(xerror.Error)
+	if !ok {
+		return nil
+	}
+	if code := xerr.Code(); code != "" {
+		enc.AddString(KeyCode, code)
+	}
+	if dbg := xerr.RenderedDebug(); len(dbg) > 0 {
+		if err := enc.AddReflected(KeyDebug, dbg); err != nil {
+			return err
+		}
+	}
+	if stack := xerr.Stack(); len(stack) > 0 {
+		if err := enc.AddArray(KeyStack, stringArray(stack)); err != nil {
+			return err
+		}
+	}
+	if fields := xerr.Fields(); len(fields) > 0 {
+		return enc.AddObject(KeyFields, fieldsObject(fields))
+	}
+	return nil
+}
+
+// fieldsObject returns a `zapcore.ObjectMarshaler` writing the given fields, sorted by key
+func fieldsObject(fields map[string]interface{}) zapcore.ObjectMarshaler {
+	return zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := enc.AddReflected(k, fields[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stringArray returns a `zapcore.ArrayMarshaler` for the given strings
+func stringArray(s []string) zapcore.ArrayMarshaler {
+	return zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, v := range s {
+			enc.AppendString(v)
+		}
+		return nil
+	})
+}
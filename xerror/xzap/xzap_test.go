@@ -0,0 +1,75 @@
+package xzap_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ibrt/go-xerror/xerror"
+	"github.com/ibrt/go-xerror/xerror/xzap"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"testing"
+)
+
+func marshal(t *testing.T, err error) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	assert.Nil(t, xzap.Object(err).MarshalLogObject(enc))
+	return enc.Fields
+}
+
+func TestObject_Error(t *testing.T) {
+	err := xerror.New("fmt %v", "p1", xerror.Secret{Value: "pw"}).WithCode("CODE").WithField("userID", 42)
+	fields := marshal(t, err)
+	assert.Equal(t, "fmt p1", fields[xzap.KeyMessage])
+	assert.Equal(t, "CODE", fields[xzap.KeyCode])
+	assert.Equal(t, "[p1 [REDACTED]]", fmt.Sprint(fields[xzap.KeyDebug]))
+	assert.Equal(t, map[string]interface{}{"userID": 42}, fields[xzap.KeyFields])
+	stack := fields[xzap.KeyStack].([]interface{})
+	assert.Equal(t, len(err.Stack()), len(stack))
+	assert.Regexp(t, `xzap_test\.go:\d+ \(.*TestObject_Error\)$`, stack[0])
+}
+
+func TestObject_CollidingField(t *testing.T) {
+	err := xerror.New("fmt").WithCode("CODE").WithField("code", "user").WithField("message", "m")
+	fields := marshal(t, err)
+	assert.Equal(t, "fmt", fields[xzap.KeyMessage])
+	assert.Equal(t, "CODE", fields[xzap.KeyCode])
+	assert.Equal(t, map[string]interface{}{"code": "user", "message": "m"}, fields[xzap.KeyFields])
+}
+
+func TestObject_DebugValueMaxLen(t *testing.T) {
+	xerror.SetDebugValueMaxLen(5)
+	defer xerror.SetDebugValueMaxLen(0)
+	assert.Equal(t, "[abcde…]", fmt.Sprint(marshal(t, xerror.New("fmt", "abcdefgh"))[xzap.KeyDebug]))
+}
+
+func TestObject_GoError(t *testing.T) {
+	assert.Equal(t, map[string]interface{}{xzap.KeyMessage: "ew"}, marshal(t, errors.New("ew")))
+}
+
+func TestObject_Nil(t *testing.T) {
+	assert.Empty(t, marshal(t, nil))
+}
+
+func ExampleObject() {
+	xerror.SetStackCaptureEnabled(false)
+	defer xerror.SetStackCaptureEnabled(true)
+
+	// With a logger: logger.Error("failed", zap.Object("error", xzap.Object(err))).
+	err := xerror.Wrap(xerror.New("user %v not found", "john"), "lookup failed").WithField("userID", 42)
+	enc := zapcore.NewMapObjectEncoder()
+	_ = xzap.Object(err).MarshalLogObject(enc)
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%v: %v\n", k, enc.Fields[k])
+	}
+	// Output:
+	// debug: [john]
+	// fields: map[userID:42]
+	// message: lookup failed: user john not found
+}